@@ -2,40 +2,101 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	charmlog "github.com/charmbracelet/log"
 	"go.uber.org/fx"
 
 	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/background"
 	"yk-dc-bot/internal/bot"
+	"yk-dc-bot/internal/circuit"
+	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/components"
 	"yk-dc-bot/internal/config"
 	"yk-dc-bot/internal/database"
-	_ "yk-dc-bot/internal/handlers"
+	"yk-dc-bot/internal/errorreport"
+	"yk-dc-bot/internal/fixtures"
+	"yk-dc-bot/internal/guildsettings"
+	"yk-dc-bot/internal/handlers"
+	"yk-dc-bot/internal/health"
 	"yk-dc-bot/internal/henrikapi"
+	"yk-dc-bot/internal/i18n"
 	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/matchstore"
+	"yk-dc-bot/internal/metrics"
 	"yk-dc-bot/internal/redisclient"
+	"yk-dc-bot/internal/schema"
 	"yk-dc-bot/internal/service"
+	"yk-dc-bot/internal/tracing"
+	"yk-dc-bot/internal/trngg"
+	"yk-dc-bot/internal/valorantapi"
 )
 
+// stopGrace is how long the hooks that stop after the bot get on shutdown.
+const stopGrace = 15 * time.Second
+
 func main() {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	loader := config.NewLoader(flag.CommandLine)
+	printConfig := flag.Bool("print-config", false, "print the resolved configuration with secrets redacted and exit")
+	flag.Parse()
+
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if *printConfig {
+		cfg.Print(os.Stdout)
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "\n%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
 
 	app := fx.New(
+		fx.Supply(cfg, loader),
 		fx.Provide(
-			config.NewConfig,
-			logger.NewLogger,
+			config.NewLogger,
+			config.NewReloader,
+			i18n.NewCatalog,
+			metrics.NewRegistry,
+			metrics.NewBot,
 			database.NewPostgresDB,
 			redisclient.NewRedisClient,
+			fixtures.NewStore,
+			circuit.NewBreakers,
+			background.NewJobs,
+			schema.NewDetector,
 			henrikapi.NewHenrikDevAPI,
-			service.NewService,
+			trngg.NewTrackerAPI,
+			valorantapi.NewValorantAPI,
+			matchstore.NewMatchStore,
+			guildsettings.NewStore,
+			errorreport.NewReporter,
+			metrics.NewServer,
+			tracing.NewTracer,
+			health.NewChecker,
+			service.NewPlayers,
+			components.NewCodec,
+			components.NewRouter,
+			commands.NewRegistry,
 			bot.NewDiscordBot,
 		),
-		fx.Invoke(runBot),
+		handlers.Module,
+		fx.Invoke(watchConfig, exportTraces, watchGuildSettings, reportErrors, serveMetrics, serveHealth, runBot),
 	)
 
 	if err := app.Start(context.Background()); err != nil {
@@ -46,15 +107,118 @@ func main() {
 	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
 	<-sc
 
+	// the bot drains for up to SHUTDOWN_TIMEOUT; the rest is for the hooks
+	// that stop after it, such as flushing error reports and spans
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout+stopGrace)
+	defer cancel()
 	if err := app.Stop(ctx); err != nil {
 		fmt.Printf("Error during shutdown: %v\n", err)
 	}
 }
 
+// watchConfig applies the log level now and on every reload, and reloads the
+// runtime settings on SIGHUP or config file changes.
+func watchConfig(lc fx.Lifecycle, reloader *config.Reloader, log *logger.Logger) {
+	reloader.Subscribe(func(runtime *config.Runtime) {
+		level, err := charmlog.ParseLevel(runtime.LogLevel)
+		if err != nil {
+			log.Warn("Ignoring invalid log level", "level", runtime.LogLevel)
+			return
+		}
+		log.SetLevel(level)
+	})
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			reloader.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			reloader.Stop()
+			return nil
+		},
+	})
+}
+
+// exportTraces sends spans to the configured exporter. It stops after the
+// bot so the spans of the last interactions are still exported.
+func exportTraces(lc fx.Lifecycle, tracer *tracing.Tracer) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			tracer.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			tracer.Stop()
+			return nil
+		},
+	})
+}
+
+// watchGuildSettings drops cached guild settings when another instance
+// changes them.
+func watchGuildSettings(lc fx.Lifecycle, store *guildsettings.Store) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			store.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			store.Stop()
+			return nil
+		},
+	})
+}
+
+// reportErrors posts failures to the ops webhook or channel. It stops after
+// the bot so the last failures still go out.
+func reportErrors(lc fx.Lifecycle, reporter *errorreport.Reporter, bot *bot.DiscordBot) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			reporter.Start(bot.Session)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			reporter.Stop()
+			return nil
+		},
+	})
+}
+
+// serveMetrics exposes /metrics, adding the gauges that are read from the
+// Discord session and the schema detector at scrape time.
+func serveMetrics(lc fx.Lifecycle, server *metrics.Server, registry *metrics.Registry, bot *bot.DiscordBot, detector *schema.Detector) {
+	registry.NewGaugeFunc("yko_gateway_latency_seconds", "Latency of the last Discord gateway heartbeat.", nil, func(emit metrics.Emit) {
+		if latency := bot.Session.HeartbeatLatency(); latency > 0 {
+			emit(latency.Seconds())
+		}
+	})
+	registry.NewCounterFunc("yko_schema_drift_total", "Upstream responses that drifted from their expected schema.", []string{"schema"}, func(emit metrics.Emit) {
+		for name, count := range detector.Counts() {
+			emit(float64(count), name)
+		}
+	})
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return server.Start()
+		},
+		OnStop: func(ctx context.Context) error {
+			return server.Stop(ctx)
+		},
+	})
+}
+
+// serveHealth adds /healthz and /readyz next to /metrics.
+func serveHealth(server *metrics.Server, checker *health.Checker) {
+	server.Handle("/healthz", http.HandlerFunc(checker.Live))
+	server.Handle("/readyz", http.HandlerFunc(checker.Ready))
+}
+
 func runBot(lc fx.Lifecycle, bot *bot.DiscordBot, log *logger.Logger) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			logger.Info("Starting bot...")
+			log.Info("Starting bot...")
 			return bot.Run(ctx)
 		},
 		OnStop: func(ctx context.Context) error {
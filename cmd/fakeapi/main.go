@@ -0,0 +1,345 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"yk-dc-bot/internal/henrikapi"
+)
+
+// fakePlayer is a canned account. Accounts are matched case-insensitively by
+// Riot ID, the same way the real APIs do.
+type fakePlayer struct {
+	Name    string
+	Tag     string
+	Puuid   string
+	Region  string
+	Tier    int
+	Rank    string
+	Elo     int
+	Agent   string
+	Private bool // tracker.gg profile is private
+}
+
+var players = []*fakePlayer{
+	{Name: "tester", Tag: "fake", Puuid: "fake-puuid-tester", Region: "eu", Tier: 18, Rank: "Diamond 1", Elo: 1542, Agent: "Jett"},
+	{Name: "rival", Tag: "fake", Puuid: "fake-puuid-rival", Region: "eu", Tier: 15, Rank: "Platinum 1", Elo: 1238, Agent: "Sova"},
+	{Name: "duo", Tag: "fake", Puuid: "fake-puuid-duo", Region: "eu", Tier: 17, Rank: "Platinum 3", Elo: 1461, Agent: "Omen"},
+	{Name: "private", Tag: "fake", Puuid: "fake-puuid-private", Region: "na", Tier: 9, Rank: "Silver 1", Elo: 640, Agent: "Sage", Private: true},
+}
+
+// rateLimitedName is answered with 429 by every endpoint; any Riot ID not in
+// players gets a 404.
+const rateLimitedName = "ratelimited"
+
+const matchCount = 5
+
+func main() {
+	addr := flag.String("addr", ":8090", "address to listen on")
+	flag.Parse()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /valorant/v2/account/{name}/{tag}", handleAccount)
+	mux.HandleFunc("GET /valorant/v2/by-puuid/account/{puuid}", handleDetailedAccount)
+	mux.HandleFunc("GET /valorant/v2/by-puuid/mmr/{region}/{puuid}", handleMMR)
+	mux.HandleFunc("GET /valorant/v3/by-puuid/matches/{region}/{puuid}", handleMatches)
+	mux.HandleFunc("GET /valorant/v2/match/{id}", handleMatch)
+	mux.HandleFunc("GET /tracker/{riotID}", handleTracker)
+
+	log.Printf("fake API listening on %s", *addr)
+	log.Printf("point the bot at it with HENRIKDEV_BASE_URL=http://localhost%s/valorant and TRACKER_BASE_URL=http://localhost%s/tracker", *addr, *addr)
+	log.Fatal(http.ListenAndServe(*addr, logRequests(mux)))
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		log.Printf("%s %s (%s)", r.Method, r.URL.RequestURI(), time.Since(start).Round(time.Microsecond))
+	})
+}
+
+func byRiotID(name, tag string) *fakePlayer {
+	for _, p := range players {
+		if strings.EqualFold(p.Name, name) && strings.EqualFold(p.Tag, tag) {
+			return p
+		}
+	}
+	return nil
+}
+
+func byPUUID(puuid string) *fakePlayer {
+	for _, p := range players {
+		if p.Puuid == puuid {
+			return p
+		}
+	}
+	return nil
+}
+
+func handleAccount(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if strings.EqualFold(name, rateLimitedName) {
+		writeHenrikError(w, http.StatusTooManyRequests, "Rate limit reached")
+		return
+	}
+	p := byRiotID(name, r.PathValue("tag"))
+	if p == nil {
+		writeHenrikError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	writeHenrik(w, henrikapi.AccountData{
+		Puuid:        p.Puuid,
+		Region:       p.Region,
+		AccountLevel: 120,
+		Name:         p.Name,
+		Tag:          p.Tag,
+		Card:         "fake-card",
+		Platforms:    []string{"PC"},
+		UpdatedAt:    time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+func handleDetailedAccount(w http.ResponseWriter, r *http.Request) {
+	p := byPUUID(r.PathValue("puuid"))
+	if p == nil {
+		writeHenrikError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	writeHenrik(w, henrikapi.DetailedAccountData{
+		Puuid:         p.Puuid,
+		Region:        p.Region,
+		AccountLevel:  120,
+		Name:          p.Name,
+		Tag:           p.Tag,
+		Card:          henrikapi.Card{ID: "fake-card"},
+		LastUpdate:    "now",
+		LastUpdateRaw: time.Now().Unix(),
+	})
+}
+
+func handleMMR(w http.ResponseWriter, r *http.Request) {
+	p := byPUUID(r.PathValue("puuid"))
+	if p == nil {
+		writeHenrikError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	var mmr henrikapi.MMRData
+	mmr.Name = p.Name
+	mmr.Tag = p.Tag
+	mmr.CurrentData.CurrentTier = p.Tier
+	mmr.CurrentData.CurrentTierPatched = p.Rank
+	mmr.CurrentData.RankingInTier = p.Elo % 100
+	mmr.CurrentData.MMRChangeToLastGame = 18
+	mmr.CurrentData.Elo = p.Elo
+	writeHenrik(w, mmr)
+}
+
+func handleMatches(w http.ResponseWriter, r *http.Request) {
+	p := byPUUID(r.PathValue("puuid"))
+	if p == nil {
+		writeHenrikError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	size := matchCount
+	if n, err := strconv.Atoi(r.URL.Query().Get("size")); err == nil && n > 0 && n < size {
+		size = n
+	}
+	matches := make([]henrikapi.MatchData, 0, size)
+	for idx := 0; idx < size; idx++ {
+		matches = append(matches, buildMatch(idx))
+	}
+	writeHenrik(w, matches)
+}
+
+func handleMatch(w http.ResponseWriter, r *http.Request) {
+	var idx int
+	if _, err := fmt.Sscanf(r.PathValue("id"), "fake-match-%d", &idx); err != nil || idx < 0 || idx >= matchCount {
+		writeHenrikError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	writeHenrik(w, buildMatch(idx))
+}
+
+// buildMatch returns a deterministic competitive match in which every canned
+// player takes part, tester and duo queueing together on red.
+func buildMatch(idx int) henrikapi.MatchData {
+	var match henrikapi.MatchData
+	match.Metadata = henrikapi.MatchMetadata{
+		Map:          []string{"Ascent", "Bind", "Haven", "Split", "Lotus"}[idx%5],
+		GameVersion:  "release-09.00",
+		GameLength:   2_100_000,
+		GameStart:    time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC).Add(-time.Duration(idx) * 24 * time.Hour).Unix(),
+		RoundsPlayed: 20,
+		Mode:         "Competitive",
+		ModeID:       "competitive",
+		Queue:        "Standard",
+		MatchID:      fmt.Sprintf("fake-match-%d", idx),
+		Region:       "eu",
+		Cluster:      "Frankfurt",
+	}
+
+	for pIdx, p := range players {
+		team, party := "Blue", ""
+		if pIdx%2 == 0 {
+			team, party = "Red", "fake-party-red"
+		}
+		kills := 12 + (pIdx*7+idx*3)%14
+		match.Players.AllPlayers = append(match.Players.AllPlayers, henrikapi.MatchPlayer{
+			Puuid:              p.Puuid,
+			Name:               p.Name,
+			Tag:                p.Tag,
+			Team:               team,
+			Level:              120,
+			Character:          p.Agent,
+			CurrentTier:        p.Tier,
+			CurrentTierPatched: p.Rank,
+			PartyID:            party,
+			Stats: henrikapi.MatchPlayerStats{
+				Score:     kills * 250,
+				Kills:     kills,
+				Deaths:    14 + (pIdx+idx)%5,
+				Assists:   3 + pIdx,
+				Bodyshots: kills * 4,
+				Headshots: kills,
+				Legshots:  pIdx,
+			},
+			DamageMade:     kills * 145,
+			DamageReceived: 2400,
+		})
+	}
+
+	redWon := idx%2 == 0
+	won, lost := henrikapi.MatchTeam{HasWon: true, RoundsWon: 13, RoundsLost: 7}, henrikapi.MatchTeam{RoundsWon: 7, RoundsLost: 13}
+	match.Teams.Red, match.Teams.Blue = won, lost
+	if !redWon {
+		match.Teams.Red, match.Teams.Blue = lost, won
+	}
+
+	for round := 0; round < match.Metadata.RoundsPlayed; round++ {
+		winner := "Blue"
+		if (round%3 != 0) == redWon {
+			winner = "Red"
+		}
+		var stats []henrikapi.RoundPlayerStats
+		for _, p := range match.Players.AllPlayers {
+			var economy henrikapi.RoundEconomy
+			economy.LoadoutValue = 800 + (round%4)*1300
+			economy.Spent = economy.LoadoutValue - 800
+			economy.Remaining = 600 + (round%5)*400
+			stats = append(stats, henrikapi.RoundPlayerStats{
+				PlayerPuuid:       p.Puuid,
+				PlayerDisplayName: p.Name + "#" + p.Tag,
+				PlayerTeam:        p.Team,
+				Damage:            p.DamageMade / match.Metadata.RoundsPlayed,
+				Kills:             (round + len(p.Name)) % 3,
+				Score:             p.Stats.Score / match.Metadata.RoundsPlayed,
+				Economy:           economy,
+			})
+		}
+		match.Rounds = append(match.Rounds, henrikapi.MatchRound{
+			WinningTeam: winner,
+			EndType:     "Eliminated",
+			BombPlanted: round%4 == 1,
+			PlayerStats: stats,
+		})
+
+		killer := match.Players.AllPlayers[round%len(match.Players.AllPlayers)]
+		victim := match.Players.AllPlayers[(round+1)%len(match.Players.AllPlayers)]
+		match.Kills = append(match.Kills, henrikapi.MatchKill{
+			KillTimeInRound:     int64(20_000 + round*1_500),
+			KillTimeInMatch:     int64(round*100_000 + 20_000),
+			Round:               round,
+			KillerPuuid:         killer.Puuid,
+			KillerDisplayName:   killer.Name + "#" + killer.Tag,
+			KillerTeam:          killer.Team,
+			VictimPuuid:         victim.Puuid,
+			VictimDisplayName:   victim.Name + "#" + victim.Tag,
+			VictimTeam:          victim.Team,
+			VictimDeathLocation: henrikapi.Location{X: 1000 + round*250, Y: -2000 + round*300},
+			DamageWeaponName:    "Vandal",
+		})
+	}
+
+	return match
+}
+
+func handleTracker(w http.ResponseWriter, r *http.Request) {
+	name, tag, _ := strings.Cut(r.PathValue("riotID"), "#")
+	if strings.EqualFold(name, rateLimitedName) {
+		writeJSON(w, http.StatusTooManyRequests, map[string]interface{}{
+			"errors": []map[string]string{{"code": "RateLimited", "message": "You are being rate limited"}},
+		})
+		return
+	}
+	p := byRiotID(name, tag)
+	if p == nil {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{
+			"errors": []map[string]string{{"code": "CollectorResultStatus::NotFound", "message": "This profile doesn't exist."}},
+		})
+		return
+	}
+	if p.Private {
+		writeJSON(w, http.StatusUnavailableForLegalReasons, map[string]interface{}{
+			"errors": []map[string]string{{"code": "CollectorResultStatus::Private", "message": "This profile is private."}},
+		})
+		return
+	}
+
+	display := func(value string) map[string]interface{} {
+		return map[string]interface{}{"displayValue": value}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{
+			"platformInfo": map[string]string{
+				"platformUserIdentifier": p.Name + "#" + p.Tag,
+				"avatarUrl":              "",
+			},
+			"segments": []map[string]interface{}{{
+				"type": "overview",
+				"stats": map[string]interface{}{
+					"matchesWon":          display("64"),
+					"matchesLost":         display("51"),
+					"matchesWinPct":       display("55.7%"),
+					"headshotsPercentage": display("24.1%"),
+					"kDRatio":             display("1.18"),
+					"damagePerRound":      display("148.2"),
+					"timePlayed":          display("61h 12m"),
+					"rank": map[string]interface{}{
+						"displayValue": p.Rank,
+						"metadata":     map[string]string{"tierName": p.Rank, "iconUrl": ""},
+					},
+				},
+			}},
+		},
+	})
+}
+
+func writeHenrik(w http.ResponseWriter, data interface{}) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": http.StatusOK, "data": data})
+}
+
+func writeHenrikError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"status": status,
+		"errors": []map[string]interface{}{{"message": message, "code": 0, "details": nil}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("encoding response: %v", err)
+	}
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 
@@ -10,18 +12,61 @@ import (
 
 	"yk-dc-bot/internal/apperrors"
 	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/components"
 	"yk-dc-bot/internal/config"
-	_ "yk-dc-bot/internal/handlers"
+	"yk-dc-bot/internal/errorreport"
+	"yk-dc-bot/internal/guildsettings"
+	"yk-dc-bot/internal/handlers"
+	"yk-dc-bot/internal/henrikapi"
+	"yk-dc-bot/internal/i18n"
 	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/matchstore"
+	"yk-dc-bot/internal/service"
+	"yk-dc-bot/internal/trngg"
+	"yk-dc-bot/internal/valorantapi"
 )
 
+type registerOptions struct {
+	GuildID string
+	DryRun  bool
+	Prune   bool
+	Export  string
+}
+
 func main() {
+	var opts registerOptions
+	flag.StringVar(&opts.GuildID, "guild", "", "register commands to this guild instead of globally (updates appear instantly)")
+	flag.BoolVar(&opts.DryRun, "dry-run", false, "print the plan without applying it")
+	flag.BoolVar(&opts.Prune, "prune", false, "delete registered commands that no longer exist locally")
+	flag.StringVar(&opts.Export, "export", "", "print the local command set in the given format (json) and exit")
+	loader := config.NewLoader(flag.CommandLine)
+	flag.Parse()
+
+	if opts.Export != "" {
+		if err := exportCommands(opts.Export); err != nil {
+			fmt.Printf("Error exporting commands: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Printf("Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+
 	app := fx.New(
+		fx.Supply(opts, cfg),
 		fx.Provide(
-			config.NewConfig,
-			logger.NewLogger,
+			config.NewLogger,
 			newDiscordSession,
 		),
+		definitions(),
 		fx.Invoke(registerCommands),
 	)
 
@@ -41,26 +86,111 @@ func newDiscordSession(cfg *config.Config) (*discordgo.Session, error) {
 	return session, nil
 }
 
-func registerCommands(session *discordgo.Session, log *logger.Logger) error {
-	log.Info("Registering commands...")
+// definitions builds the command registry from the feature modules. Handlers
+// never run here, so the features get empty clients and a codec without Redis
+// rather than real connections.
+func definitions() fx.Option {
+	return fx.Options(
+		fx.Supply(
+			&henrikapi.HenrikDevAPI{}, &trngg.TrackerAPI{}, &valorantapi.ValorantAPI{}, &matchstore.Store{}, &service.Players{},
+			&components.Codec{}, &guildsettings.Store{}, &errorreport.Reporter{},
+		),
+		handlers.Module,
+		fx.Provide(i18n.NewCatalog, commands.NewRegistry),
+	)
+}
+
+func exportCommands(format string) error {
+	if format != "json" {
+		return apperrors.New("EXPORT_FORMAT_ERROR", fmt.Sprintf("unsupported export format %q", format))
+	}
+
+	// export every feature, whatever the local .env disables
+	var registry *commands.Registry
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(&config.Config{}),
+		definitions(),
+		fx.Populate(&registry),
+	)
+	if err := app.Err(); err != nil {
+		return apperrors.Wrap(err, "EXPORT_ERROR", "failed to build command registry")
+	}
+
+	out, err := json.MarshalIndent(registry.All(), "", "  ")
+	if err != nil {
+		return apperrors.Wrap(err, "EXPORT_ERROR", "failed to encode commands")
+	}
+
+	fmt.Println(string(out))
+	return nil
+}
+
+func registerCommands(session *discordgo.Session, registry *commands.Registry, log *logger.Logger, opts registerOptions) error {
+	scope := "global"
+	if opts.GuildID != "" {
+		scope = "guild " + opts.GuildID
+	}
+	log.Info("Syncing commands...", "scope", scope, "dry_run", opts.DryRun, "prune", opts.Prune)
+
+	app, err := session.User("@me")
+	if err != nil {
+		return apperrors.Wrap(err, "DISCORD_APPLICATION_ERROR", "Error fetching bot application")
+	}
 
-	err := session.Open()
+	existing, err := session.ApplicationCommands(app.ID, opts.GuildID)
 	if err != nil {
-		return apperrors.Wrap(err, "DISCORD_SESSION_OPEN_ERROR", "Error opening Discord session", "")
+		return apperrors.Wrap(err, "COMMAND_FETCH_ERROR", "Error fetching registered commands")
 	}
-	defer session.Close()
 
-	commands.RegisterAll()
+	plan := commands.Diff(existing, registry.All(), opts.Prune)
 
-	for _, cmd := range commands.GetAll() {
-		log.Info(fmt.Sprintf("Registering command: %s", cmd.Name))
-		_, err := session.ApplicationCommandCreate(session.State.User.ID, "", cmd)
-		if err != nil {
-			return apperrors.Wrap(err, "COMMAND_REGISTRATION_ERROR", fmt.Sprintf("Error registering command %s", cmd.Name))
-		}
-		log.Info(fmt.Sprintf("Successfully registered command: %s", cmd.Name))
+	fmt.Printf("\nCommand plan (%s):\n", scope)
+	for _, change := range plan.Changes {
+		fmt.Printf("  %s %-10s %s\n", planSymbol(change.Kind), change.Kind, change.Name)
 	}
+	fmt.Printf("\n%d to create, %d to update, %d to delete, %d stale kept, %d unchanged\n\n",
+		plan.Count(commands.ChangeCreate),
+		plan.Count(commands.ChangeUpdate),
+		plan.Count(commands.ChangeDelete),
+		plan.Count(commands.ChangeKeep),
+		plan.Count(commands.ChangeUnchanged),
+	)
 
-	log.Info("All commands registered successfully!")
+	if plan.Count(commands.ChangeKeep) > 0 {
+		log.Warn("Stale commands are still registered; rerun with --prune to delete them")
+	}
+
+	if !plan.HasChanges() {
+		log.Info("Commands are already up to date")
+		return nil
+	}
+
+	if opts.DryRun {
+		log.Info("Dry run, not applying plan")
+		return nil
+	}
+
+	registered, err := session.ApplicationCommandBulkOverwrite(app.ID, opts.GuildID, plan.Commands())
+	if err != nil {
+		return apperrors.Wrap(err, "COMMAND_REGISTRATION_ERROR", "Error applying command plan")
+	}
+
+	log.Info("Commands synced successfully!", "registered", len(registered))
 	return nil
 }
+
+func planSymbol(kind commands.ChangeKind) string {
+	switch kind {
+	case commands.ChangeCreate:
+		return "+"
+	case commands.ChangeUpdate:
+		return "~"
+	case commands.ChangeDelete:
+		return "-"
+	case commands.ChangeKeep:
+		return "!"
+	default:
+		return "="
+	}
+}
@@ -2,7 +2,13 @@ module yk-dc-bot
 
 go 1.23.0
 
-require github.com/bwmarrin/discordgo v0.28.1
+require (
+	github.com/bwmarrin/discordgo v0.28.1
+	github.com/charmbracelet/log v0.4.0
+	github.com/gospider007/ja3 v0.0.0-20240620005139-f0602f169903
+	github.com/redis/go-redis/v9 v9.6.1
+	github.com/tidwall/gjson v1.17.3
+)
 
 require (
 	github.com/PuerkitoBio/goquery v1.9.2 // indirect
@@ -13,7 +19,6 @@ require (
 	github.com/caddyserver/zerossl v0.1.3 // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/charmbracelet/lipgloss v0.10.0 // indirect
-	github.com/charmbracelet/log v0.4.0 // indirect
 	github.com/cloudflare/circl v1.4.0 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/go-logfmt/logfmt v0.6.0 // indirect
@@ -23,7 +28,6 @@ require (
 	github.com/gospider007/bs4 v0.0.0-20240531060354-fe6c0582dfd9 // indirect
 	github.com/gospider007/gson v0.0.0-20240528092941-f4f87ed18978 // indirect
 	github.com/gospider007/gtls v0.0.0-20240527084326-e580531eb89e // indirect
-	github.com/gospider007/ja3 v0.0.0-20240620005139-f0602f169903 // indirect
 	github.com/gospider007/kinds v0.0.0-20231024093643-7a4424f2d30e // indirect
 	github.com/gospider007/net v0.0.0-20240620005014-93bab3eb6b6c // indirect
 	github.com/gospider007/re v0.0.0-20240227100911-e27255e48eff // indirect
@@ -43,10 +47,8 @@ require (
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/muesli/reflow v0.3.0 // indirect
 	github.com/muesli/termenv v0.15.2 // indirect
-	github.com/redis/go-redis/v9 v9.6.1 // indirect
 	github.com/refraction-networking/utls v1.6.7 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
-	github.com/tidwall/gjson v1.17.3 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.1 // indirect
 	github.com/zeebo/blake3 v0.2.4 // indirect
@@ -60,7 +62,7 @@ require (
 
 require (
 	github.com/fogleman/gg v1.3.0
-	github.com/fsnotify/fsnotify v1.7.0 // indirect
+	github.com/fsnotify/fsnotify v1.7.0
 	github.com/gorilla/websocket v1.5.1 // indirect
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/jmoiron/sqlx v1.4.0
@@ -75,11 +77,11 @@ require (
 	github.com/spf13/afero v1.11.0 // indirect
 	github.com/spf13/cast v1.6.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	github.com/spf13/viper v1.19.0 // indirect
+	github.com/spf13/viper v1.19.0
 	github.com/subosito/gotenv v1.6.0 // indirect
 	go.uber.org/atomic v1.9.0 // indirect
 	go.uber.org/dig v1.18.0 // indirect
-	go.uber.org/fx v1.22.2 // indirect
+	go.uber.org/fx v1.22.2
 	go.uber.org/multierr v1.11.0 // indirect
 	go.uber.org/zap v1.27.0 // indirect
 	golang.org/x/crypto v0.26.0 // indirect
@@ -0,0 +1,227 @@
+package analysis
+
+import (
+	"sort"
+	"time"
+
+	"yk-dc-bot/internal/henrikapi"
+)
+
+const DefaultTradeWindow = 5 * time.Second
+
+type Options struct {
+	TradeWindow time.Duration
+}
+
+type Option func(*Options)
+
+func WithTradeWindow(window time.Duration) Option {
+	return func(o *Options) {
+		o.TradeWindow = window
+	}
+}
+
+type ClutchRecord struct {
+	Attempts int
+	Wins     int
+}
+
+type PlayerMetrics struct {
+	Matches      int
+	Rounds       int
+	Kills        int
+	Deaths       int
+	Assists      int
+	KASTRounds   int
+	FirstKills   int
+	FirstDeaths  int
+	TradeKills   int
+	TradedDeaths int
+	Clutches     map[int]*ClutchRecord
+}
+
+func (m *PlayerMetrics) KAST() float64 {
+	return percent(m.KASTRounds, m.Rounds)
+}
+
+func (m *PlayerMetrics) FirstKillRatio() float64 {
+	if m.FirstDeaths == 0 {
+		return float64(m.FirstKills)
+	}
+	return float64(m.FirstKills) / float64(m.FirstDeaths)
+}
+
+func (m *PlayerMetrics) TradeKillRate() float64 {
+	return percent(m.TradeKills, m.Kills)
+}
+
+func (m *PlayerMetrics) TradedDeathRate() float64 {
+	return percent(m.TradedDeaths, m.Deaths)
+}
+
+func (m *PlayerMetrics) ClutchTotals() (attempts, wins int) {
+	for _, record := range m.Clutches {
+		attempts += record.Attempts
+		wins += record.Wins
+	}
+	return attempts, wins
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+func AnalyzePlayer(puuid string, matches []henrikapi.MatchData, opts ...Option) *PlayerMetrics {
+	options := Options{TradeWindow: DefaultTradeWindow}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	metrics := &PlayerMetrics{Clutches: make(map[int]*ClutchRecord)}
+	for idx := range matches {
+		analyzeMatch(puuid, &matches[idx], options, metrics)
+	}
+	return metrics
+}
+
+func analyzeMatch(puuid string, match *henrikapi.MatchData, options Options, metrics *PlayerMetrics) {
+	player, ok := match.Player(puuid)
+	if !ok {
+		return
+	}
+
+	metrics.Matches++
+	metrics.Rounds += len(match.Rounds)
+	metrics.Kills += player.Stats.Kills
+	metrics.Deaths += player.Stats.Deaths
+	metrics.Assists += player.Stats.Assists
+
+	teamSizes := make(map[string]int)
+	for _, p := range match.Players.AllPlayers {
+		teamSizes[p.Team]++
+	}
+
+	killsByRound := KillsByRound(match)
+	window := options.TradeWindow.Milliseconds()
+
+	for roundIdx, round := range match.Rounds {
+		kills := killsByRound[roundIdx]
+
+		alive := make(map[string]int, len(teamSizes))
+		for team, size := range teamSizes {
+			alive[team] = size
+		}
+
+		var gotKill, gotAssist, died, traded bool
+		clutchSize := 0
+
+		for killIdx, kill := range kills {
+			if killIdx == 0 {
+				if kill.KillerPuuid == puuid {
+					metrics.FirstKills++
+				}
+				if kill.VictimPuuid == puuid {
+					metrics.FirstDeaths++
+				}
+			}
+
+			if kill.KillerPuuid == puuid && kill.VictimTeam != player.Team {
+				gotKill = true
+				if isTradeKill(kills[:killIdx], kill, puuid, window) {
+					metrics.TradeKills++
+				}
+			}
+
+			for _, assistant := range kill.Assistants {
+				if assistant.AssistantPuuid == puuid {
+					gotAssist = true
+				}
+			}
+
+			if kill.VictimPuuid == puuid {
+				died = true
+				if isTradedDeath(kills[killIdx+1:], kill, window) {
+					traded = true
+					metrics.TradedDeaths++
+				}
+			}
+
+			alive[kill.VictimTeam]--
+
+			if clutchSize == 0 && !died && alive[player.Team] == 1 {
+				enemies := 0
+				for team, count := range alive {
+					if team != player.Team {
+						enemies += count
+					}
+				}
+				if enemies > 0 {
+					clutchSize = enemies
+				}
+			}
+		}
+
+		if gotKill || gotAssist || !died || traded {
+			metrics.KASTRounds++
+		}
+
+		if clutchSize > 0 {
+			record, ok := metrics.Clutches[clutchSize]
+			if !ok {
+				record = &ClutchRecord{}
+				metrics.Clutches[clutchSize] = record
+			}
+			record.Attempts++
+			if round.WinningTeam == player.Team {
+				record.Wins++
+			}
+		}
+	}
+}
+
+// isTradeKill reports whether kill avenged a teammate who was killed by the
+// same victim within the trade window.
+func isTradeKill(earlier []henrikapi.MatchKill, kill henrikapi.MatchKill, puuid string, window int64) bool {
+	for _, prev := range earlier {
+		if prev.KillerPuuid == kill.VictimPuuid &&
+			prev.VictimTeam == kill.KillerTeam &&
+			prev.VictimPuuid != puuid &&
+			kill.KillTimeInRound-prev.KillTimeInRound <= window {
+			return true
+		}
+	}
+	return false
+}
+
+// isTradedDeath reports whether a teammate killed death's killer within the
+// trade window.
+func isTradedDeath(later []henrikapi.MatchKill, death henrikapi.MatchKill, window int64) bool {
+	for _, next := range later {
+		if next.KillTimeInRound-death.KillTimeInRound > window {
+			return false
+		}
+		if next.VictimPuuid == death.KillerPuuid && next.KillerTeam == death.VictimTeam {
+			return true
+		}
+	}
+	return false
+}
+
+// KillsByRound groups the kill feed by (zero-based) round index, ordered by
+// time in round.
+func KillsByRound(match *henrikapi.MatchData) map[int][]henrikapi.MatchKill {
+	byRound := make(map[int][]henrikapi.MatchKill, len(match.Rounds))
+	for _, kill := range match.Kills {
+		byRound[kill.Round] = append(byRound[kill.Round], kill)
+	}
+	for round := range byRound {
+		kills := byRound[round]
+		sort.SliceStable(kills, func(a, b int) bool {
+			return kills[a].KillTimeInRound < kills[b].KillTimeInRound
+		})
+	}
+	return byRound
+}
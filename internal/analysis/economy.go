@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"math"
+	"strings"
+
+	"yk-dc-bot/internal/henrikapi"
+)
+
+const (
+	BuyPistol = "pistol"
+	BuyEco    = "eco"
+	BuyForce  = "force"
+	BuyFull   = "full"
+)
+
+const (
+	ecoLoadoutLimit   = 6000
+	forceLoadoutLimit = 20000
+
+	// winProbabilityScale is the loadout difference at which the favoured
+	// team's estimated win chance reaches roughly 73%.
+	winProbabilityScale = 6000.0
+)
+
+const (
+	OutcomeElimination = "elimination"
+	OutcomeSpike       = "spike"
+	OutcomeDefuse      = "defuse"
+	OutcomeTime        = "time"
+	OutcomeSurrender   = "surrender"
+)
+
+type TeamEconomy struct {
+	Loadout int
+	Credits int
+	Spent   int
+	Buy     string
+}
+
+type RoundEconomy struct {
+	Round              int
+	Winner             string
+	Outcome            string
+	Red                TeamEconomy
+	Blue               TeamEconomy
+	RedWinProbability  float64
+	BlueWinProbability float64
+}
+
+func MatchEconomy(match *henrikapi.MatchData) []RoundEconomy {
+	rounds := make([]RoundEconomy, 0, len(match.Rounds))
+
+	for idx, round := range match.Rounds {
+		economy := RoundEconomy{
+			Round:   idx,
+			Winner:  round.WinningTeam,
+			Outcome: RoundOutcome(round.EndType),
+		}
+
+		for _, stats := range round.PlayerStats {
+			team := &economy.Blue
+			if strings.EqualFold(stats.PlayerTeam, "red") {
+				team = &economy.Red
+			}
+			team.Loadout += stats.Economy.LoadoutValue
+			team.Spent += stats.Economy.Spent
+			team.Credits += stats.Economy.Spent + stats.Economy.Remaining
+		}
+
+		economy.Red.Buy = BuyType(idx, economy.Red.Loadout)
+		economy.Blue.Buy = BuyType(idx, economy.Blue.Loadout)
+
+		diff := float64(economy.Red.Loadout - economy.Blue.Loadout)
+		economy.RedWinProbability = 1 / (1 + math.Exp(-diff/winProbabilityScale))
+		economy.BlueWinProbability = 1 - economy.RedWinProbability
+
+		rounds = append(rounds, economy)
+	}
+
+	return rounds
+}
+
+func BuyType(round, teamLoadout int) string {
+	switch {
+	case round == 0 || round == 12:
+		return BuyPistol
+	case teamLoadout < ecoLoadoutLimit:
+		return BuyEco
+	case teamLoadout < forceLoadoutLimit:
+		return BuyForce
+	default:
+		return BuyFull
+	}
+}
+
+func RoundOutcome(endType string) string {
+	switch strings.ToLower(endType) {
+	case "bomb detonated":
+		return OutcomeSpike
+	case "bomb defused":
+		return OutcomeDefuse
+	case "round timer expired":
+		return OutcomeTime
+	case "surrendered":
+		return OutcomeSurrender
+	default:
+		return OutcomeElimination
+	}
+}
@@ -0,0 +1,88 @@
+package analysis
+
+import (
+	"strings"
+
+	"yk-dc-bot/internal/henrikapi"
+)
+
+const (
+	SideAttack  = "attack"
+	SideDefense = "defense"
+)
+
+// AttackingTeam returns the team attacking in a zero-based round. Red attacks
+// the first half, Blue the second, and overtime alternates starting with Red.
+func AttackingTeam(round int) string {
+	switch {
+	case round < 12:
+		return "Red"
+	case round < 24:
+		return "Blue"
+	case (round-24)%2 == 0:
+		return "Red"
+	default:
+		return "Blue"
+	}
+}
+
+func Side(team string, round int) string {
+	if strings.EqualFold(team, AttackingTeam(round)) {
+		return SideAttack
+	}
+	return SideDefense
+}
+
+type PositionFilter struct {
+	Map   string
+	Side  string
+	Agent string
+}
+
+type Positions struct {
+	Matches int
+	Kills   []henrikapi.Location
+	Deaths  []henrikapi.Location
+}
+
+func CollectPositions(puuid string, matches []henrikapi.MatchData, filter PositionFilter) *Positions {
+	positions := &Positions{}
+
+	for idx := range matches {
+		match := &matches[idx]
+		if filter.Map != "" && !strings.EqualFold(match.Metadata.Map, filter.Map) {
+			continue
+		}
+
+		player, ok := match.Player(puuid)
+		if !ok {
+			continue
+		}
+		if filter.Agent != "" && !strings.EqualFold(player.Character, filter.Agent) {
+			continue
+		}
+
+		positions.Matches++
+
+		for _, kill := range match.Kills {
+			if filter.Side != "" && Side(player.Team, kill.Round) != filter.Side {
+				continue
+			}
+
+			if kill.KillerPuuid == puuid && kill.VictimTeam != player.Team {
+				for _, loc := range kill.PlayerLocationsOnKill {
+					if loc.PlayerPuuid == puuid {
+						positions.Kills = append(positions.Kills, loc.Location)
+						break
+					}
+				}
+			}
+
+			if kill.VictimPuuid == puuid {
+				positions.Deaths = append(positions.Deaths, kill.VictimDeathLocation)
+			}
+		}
+	}
+
+	return positions
+}
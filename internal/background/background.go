@@ -0,0 +1,80 @@
+package background
+
+import (
+	"context"
+	"sync"
+)
+
+// Jobs tracks work started on behalf of an interaction that may outlive it,
+// such as a shared download, so shutdown can wait for it alongside the
+// interactions themselves.
+type Jobs struct {
+	mu       sync.Mutex
+	stopping bool
+	wg       sync.WaitGroup
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewJobs() *Jobs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Jobs{ctx: ctx, cancel: cancel}
+}
+
+// Go runs fn in its own goroutine. Its context keeps parent's values but not
+// its cancellation, and is cancelled instead when Wait gives up on the jobs.
+// Jobs started once Wait has been called run with a cancelled context. A nil
+// Jobs runs fn untracked.
+func (j *Jobs) Go(parent context.Context, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+	if j == nil {
+		go func() {
+			defer cancel()
+			fn(ctx)
+		}()
+		return
+	}
+
+	j.mu.Lock()
+	if j.stopping {
+		j.mu.Unlock()
+		cancel()
+		go fn(ctx)
+		return
+	}
+	j.wg.Add(1)
+	j.mu.Unlock()
+
+	stop := context.AfterFunc(j.ctx, cancel)
+	go func() {
+		defer j.wg.Done()
+		defer cancel()
+		defer stop()
+		fn(ctx)
+	}()
+}
+
+// Wait stops tracking new jobs and waits for the running ones until ctx is
+// done, then cancels whatever is left. It returns ctx's error if it had to.
+func (j *Jobs) Wait(ctx context.Context) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	j.stopping = true
+	j.mu.Unlock()
+
+	idle := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(idle)
+	}()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		j.cancel()
+		return ctx.Err()
+	}
+}
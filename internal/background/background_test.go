@@ -0,0 +1,72 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type key struct{}
+
+func TestWaitLetsJobsFinish(t *testing.T) {
+	jobs := NewJobs()
+	parent, cancelParent := context.WithCancel(context.WithValue(context.Background(), key{}, "request"))
+
+	finished := make(chan string, 1)
+	jobs.Go(parent, func(ctx context.Context) {
+		time.Sleep(20 * time.Millisecond)
+		if ctx.Err() != nil {
+			finished <- "cancelled"
+			return
+		}
+		finished <- ctx.Value(key{}).(string)
+	})
+	// the interaction that started the job gives up; the job carries on
+	cancelParent()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := jobs.Wait(ctx); err != nil {
+		t.Fatalf("Wait = %v, want the job to finish in time", err)
+	}
+	if got := <-finished; got != "request" {
+		t.Errorf("job saw %q, want it to keep the request's values and run to the end", got)
+	}
+}
+
+func TestWaitCancelsJobsPastTheDeadline(t *testing.T) {
+	jobs := NewJobs()
+
+	cancelled := make(chan struct{})
+	jobs.Go(context.Background(), func(ctx context.Context) {
+		<-ctx.Done()
+		close(cancelled)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := jobs.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait = %v, want the deadline", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("job wasn't cancelled")
+	}
+}
+
+func TestJobsStartedDuringShutdownAreCancelled(t *testing.T) {
+	jobs := NewJobs()
+	if err := jobs.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 1)
+	jobs.Go(context.Background(), func(ctx context.Context) { errs <- ctx.Err() })
+
+	if err := <-errs; err == nil {
+		t.Error("job started after Wait ran with a live context")
+	}
+}
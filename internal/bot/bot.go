@@ -3,39 +3,58 @@ package bot
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/background"
 	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/components"
 	"yk-dc-bot/internal/config"
-	"yk-dc-bot/internal/handlers"
+	"yk-dc-bot/internal/errorreport"
+	"yk-dc-bot/internal/guildsettings"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
 	"yk-dc-bot/internal/logger"
-	"yk-dc-bot/internal/service"
+	"yk-dc-bot/internal/metrics"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 type DiscordBot struct {
-	Session *discordgo.Session
-	Service *service.Service
-	Log     *logger.Logger
-	Config  *config.Config
+	Session    *discordgo.Session
+	Log        *logger.Logger
+	Config     *config.Config
+	Catalog    *i18n.Catalog
+	Commands   *commands.Registry
+	Components *components.Router
+	Errors     *errorreport.Reporter
+	Metrics    *metrics.Bot
+
+	inflight *inflight
+	jobs     *background.Jobs
 }
 
-func NewDiscordBot(cfg *config.Config, service *service.Service, log *logger.Logger) (*DiscordBot, error) {
+func NewDiscordBot(cfg *config.Config, log *logger.Logger, catalog *i18n.Catalog, registry *commands.Registry, router *components.Router, guilds *guildsettings.Store, errors *errorreport.Reporter, m *metrics.Bot, jobs *background.Jobs) (*DiscordBot, error) {
 	session, err := discordgo.New("Bot " + cfg.DiscordBotToken)
 	if err != nil {
 		return nil, apperrors.Wrap(err, "DISCORD_SESSION_ERROR", "error creating Discord session")
 	}
 
 	bot := &DiscordBot{
-		Session: session,
-		Service: service,
-		Log:     log,
-		Config:  cfg,
+		Session:    session,
+		Log:        log,
+		Config:     cfg,
+		Catalog:    catalog,
+		Commands:   registry,
+		Components: router,
+		Errors:     errors,
+		Metrics:    m,
+		inflight:   newInflight(),
+		jobs:       jobs,
 	}
 
+	registry.Use(commands.Recover(), commands.Logging(), commands.Metrics(m), commands.GuildSettings(guilds))
 	bot.registerHandlers()
-	commands.RegisterAll()
 
 	return bot, nil
 }
@@ -46,42 +65,82 @@ func (bot *DiscordBot) Run(ctx context.Context) error {
 		return fmt.Errorf("error opening connection: %w", err)
 	}
 	bot.Log.Info("Bot is now running. Press CTRL-C to exit.")
-
-	<-ctx.Done()
 	return nil
 }
 
+// Stop stops accepting interactions and waits up to ShutdownTimeout for the
+// running ones and the background jobs to finish. Interactions that don't are
+// told the bot is restarting and jobs that don't are cancelled before the
+// session is closed.
 func (bot *DiscordBot) Stop(ctx context.Context) error {
+	if running := bot.inflight.count(); running > 0 {
+		bot.Log.Info("Waiting for in-flight interactions", "count", running, "timeout", bot.Config.ShutdownTimeout)
+	}
+	drainCtx, cancel := context.WithTimeout(ctx, bot.Config.ShutdownTimeout)
+	defer cancel()
+
+	if pending := bot.inflight.drain(drainCtx); len(pending) > 0 {
+		bot.Log.Warn("Interrupting unfinished interactions", "count", len(pending))
+		var wg sync.WaitGroup
+		for _, ictx := range pending {
+			wg.Add(1)
+			go func(ictx *interaction.Ctx) {
+				defer wg.Done()
+				ictx.Interrupt()
+			}(ictx)
+		}
+		wg.Wait()
+	}
+
+	if err := bot.jobs.Wait(drainCtx); err != nil {
+		bot.Log.Warn("Cancelled unfinished background jobs", "error", err)
+	}
+
 	return bot.Session.Close()
 }
 
 func (bot *DiscordBot) registerHandlers() {
-	bot.Session.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		switch i.Type {
-		case discordgo.InteractionApplicationCommand:
-			if cmd, ok := commands.Get(i.ApplicationCommandData().Name); ok {
-				cmd.Handler(s, i, bot.Service, bot.Log, bot.Config)
-			} else {
-				bot.Log.Error("Unknown command", "name", i.ApplicationCommandData().Name)
-			}
-		case discordgo.InteractionModalSubmit:
-			for _, handler := range handlers.ModalHandlers {
-				if handler.CustomID == i.ModalSubmitData().CustomID {
-					handler.Handler(s, i, bot.Service, bot.Log, bot.Config)
-					return
-				}
-			}
+	bot.Session.AddHandler(bot.Handle)
+}
+
+// Handle runs an interaction through the global middlewares and the matching
+// command, component or autocomplete handler, tracking it until it is done
+// so Stop can drain it.
+func (bot *DiscordBot) Handle(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx := interaction.New(s, i, bot.Catalog, bot.Log, bot.Config)
+	ctx.Errors = bot.Errors
+	ctx.Metrics = bot.Metrics
+
+	if !bot.inflight.add(ctx) {
+		if i.Type != discordgo.InteractionApplicationCommandAutocomplete {
+			ctx.Interrupt()
 		}
-	})
-
-	bot.Session.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		if i.Type == discordgo.InteractionApplicationCommandAutocomplete {
-			for _, handler := range handlers.AutocompleteHandlers {
-				if handler.Name == i.ApplicationCommandData().Name {
-					handler.Handler(s, i, bot.Service, bot.Log, bot.Config)
-					return
-				}
-			}
+		ctx.Close()
+		return
+	}
+	// Close waits for the progress trackers, so their last edits count as
+	// in flight too
+	defer bot.inflight.done(ctx)
+	defer ctx.Close()
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		if route, ok := bot.Commands.Resolve(i.ApplicationCommandData()); ok {
+			route.Handler(ctx)
+		} else {
+			ctx.Log.Error("Unknown command", "name", i.ApplicationCommandData().Name)
 		}
-	})
+	case discordgo.InteractionMessageComponent:
+		if !bot.Components.Dispatch(ctx) {
+			ctx.Log.Error("Unknown component", "custom_id", i.MessageComponentData().CustomID)
+		}
+	case discordgo.InteractionModalSubmit:
+		if !bot.Components.Dispatch(ctx) {
+			ctx.Log.Error("Unknown modal", "custom_id", i.ModalSubmitData().CustomID)
+		}
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		if handler, ok := bot.Commands.ResolveAutocomplete(i.ApplicationCommandData()); ok {
+			handler(ctx)
+		}
+	}
 }
@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"context"
+	"sync"
+
+	"yk-dc-bot/internal/interaction"
+)
+
+// inflight tracks the interactions whose handlers are still running, so Stop
+// can wait for them before closing the session.
+type inflight struct {
+	mu       sync.Mutex
+	draining bool
+	running  map[*interaction.Ctx]struct{}
+	wg       sync.WaitGroup
+}
+
+func newInflight() *inflight {
+	return &inflight{running: make(map[*interaction.Ctx]struct{})}
+}
+
+// add registers ctx, or returns false once draining has started.
+func (f *inflight) add(ctx *interaction.Ctx) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.draining {
+		return false
+	}
+	f.running[ctx] = struct{}{}
+	f.wg.Add(1)
+	return true
+}
+
+func (f *inflight) done(ctx *interaction.Ctx) {
+	f.mu.Lock()
+	delete(f.running, ctx)
+	f.mu.Unlock()
+	f.wg.Done()
+}
+
+func (f *inflight) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.running)
+}
+
+// drain stops accepting interactions and waits for the running ones until
+// ctx is done. It returns those that didn't finish.
+func (f *inflight) drain(ctx context.Context) []*interaction.Ctx {
+	f.mu.Lock()
+	f.draining = true
+	f.mu.Unlock()
+
+	idle := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(idle)
+	}()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pending := make([]*interaction.Ctx, 0, len(f.running))
+	for ctx := range f.running {
+		pending = append(pending, ctx)
+	}
+	return pending
+}
@@ -0,0 +1,170 @@
+package circuit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"yk-dc-bot/internal/apperrors"
+)
+
+const (
+	// threshold is how many failures in a row open a breaker.
+	threshold = 5
+	// cooldown is how long an open breaker rejects calls before letting a
+	// single probe through.
+	cooldown = 30 * time.Second
+)
+
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker stops calls to an upstream that keeps failing, so commands fail
+// fast instead of each waiting out the same timeout.
+type Breaker struct {
+	name string
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// Breakers holds a breaker per upstream, so the health checks can report on
+// all of them.
+type Breakers struct {
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+func NewBreakers() *Breakers {
+	return &Breakers{breakers: make(map[string]*Breaker)}
+}
+
+// Get returns the breaker for an upstream, creating it on first use.
+func (r *Breakers) Get(name string) *Breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.breakers[name]
+	if !ok {
+		b = &Breaker{name: name}
+		r.breakers[name] = b
+	}
+	return b
+}
+
+// Allow returns an error if the breaker is open. Once the cooldown has passed
+// it lets one call through to probe the upstream.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if time.Since(b.openedAt) < cooldown {
+			return b.unavailable()
+		}
+		b.state = HalfOpen
+		b.probing = true
+		return nil
+	case HalfOpen:
+		if b.probing {
+			return b.unavailable()
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record reports the outcome of an allowed call.
+func (b *Breaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.state = Closed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == HalfOpen || b.failures >= threshold {
+		b.state = Open
+		b.openedAt = time.Now()
+	}
+}
+
+// Skip releases an allowed call without counting it, for calls the caller
+// gave up on before the upstream answered.
+func (b *Breaker) Skip() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+// RecordErr records a call that got no response, unless ctx was cancelled.
+func (b *Breaker) RecordErr(ctx context.Context) {
+	if ctx.Err() != nil {
+		b.Skip()
+		return
+	}
+	b.Record(false)
+}
+
+func (b *Breaker) unavailable() error {
+	return apperrors.New("UPSTREAM_UNAVAILABLE", fmt.Sprintf("%s circuit is open after %d failures", b.name, b.failures),
+		"That service isn't responding right now, please try again in a bit.")
+}
+
+// Status is a breaker's state at a point in time.
+type Status struct {
+	Name     string    `json:"-"`
+	State    string    `json:"state"`
+	Failures int       `json:"failures"`
+	RetryAt  time.Time `json:"retry_at,omitempty"`
+}
+
+// Statuses returns every breaker's current state, sorted by name.
+func (r *Breakers) Statuses() []Status {
+	r.mu.Lock()
+	all := make([]*Breaker, 0, len(r.breakers))
+	for _, b := range r.breakers {
+		all = append(all, b)
+	}
+	r.mu.Unlock()
+
+	statuses := make([]Status, 0, len(all))
+	for _, b := range all {
+		b.mu.Lock()
+		status := Status{Name: b.name, State: b.state.String(), Failures: b.failures}
+		if b.state == Open {
+			status.RetryAt = b.openedAt.Add(cooldown)
+		}
+		b.mu.Unlock()
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(a, b int) bool { return statuses[a].Name < statuses[b].Name })
+	return statuses
+}
@@ -1,52 +1,249 @@
 package commands
 
 import (
+	"sort"
+	"strings"
 	"sync"
 
-	"yk-dc-bot/internal/config"
-	"yk-dc-bot/internal/logger"
-	"yk-dc-bot/internal/service"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/redisclient"
 
 	"github.com/bwmarrin/discordgo"
+	"go.uber.org/fx"
 )
 
-type CommandHandler func(s *discordgo.Session, i *discordgo.InteractionCreate, svc *service.Service, log *logger.Logger, cfg *config.Config)
+type CommandHandler func(ctx *interaction.Ctx)
 
 type Command struct {
 	Name        string
 	Description string
 	Options     []*discordgo.ApplicationCommandOption
 	Handler     CommandHandler
+	// DefaultMemberPermissions hides the command from members without these
+	// permissions until a server admin changes it. Enforce them with
+	// RequirePermissions as well, since admins can override the default.
+	DefaultMemberPermissions *int64
+	// DMPermission set to false hides the command in direct messages.
+	DMPermission *bool
+	// Autocomplete answers autocomplete interactions for the command's options.
+	Autocomplete CommandHandler
+	Middlewares  []Middleware
+	// Cooldowns apply to the command and, for commands with subcommands, are
+	// counted separately for each subcommand.
+	Cooldowns []Cooldown
+
+	// Subcommands and Groups replace Options and Handler; Discord doesn't
+	// allow a command with subcommands to be invoked on its own.
+	Subcommands []*Subcommand
+	Groups      []*SubcommandGroup
 }
 
-var (
-	registry = make(map[string]*Command)
-	mu       sync.RWMutex
-)
+type Subcommand struct {
+	Name         string
+	Description  string
+	Options      []*discordgo.ApplicationCommandOption
+	Handler      CommandHandler
+	Autocomplete CommandHandler
+	Middlewares  []Middleware
+	Cooldowns    []Cooldown
+}
+
+type SubcommandGroup struct {
+	Name        string
+	Description string
+	Subcommands []*Subcommand
+}
+
+// Route is a resolved invocation: the full "/group sub" path, its handler and
+// the options passed to the leaf subcommand.
+type Route struct {
+	Path    string
+	Handler CommandHandler
+	Options []*discordgo.ApplicationCommandInteractionDataOption
+}
+
+type handlerEntry struct {
+	handler      CommandHandler
+	autocomplete CommandHandler
+	middlewares  []Middleware
+}
+
+// Registry holds the commands provided by the enabled feature modules and
+// resolves invocations to their handlers.
+type Registry struct {
+	commands    map[string]*Command
+	handlers    map[string]handlerEntry
+	middlewares []Middleware
+	limiter     Limiter
+	catalog     *i18n.Catalog
+	mu          sync.RWMutex
+}
+
+type RegistryParams struct {
+	fx.In
+
+	Commands []*Command `group:"commands"`
+	Catalog  *i18n.Catalog
+	// Redis counts cooldowns. Without it cooldowns aren't enforced.
+	Redis *redisclient.Client `optional:"true"`
+}
+
+func NewRegistry(p RegistryParams) *Registry {
+	r := &Registry{
+		commands: make(map[string]*Command),
+		handlers: make(map[string]handlerEntry),
+		catalog:  p.Catalog,
+	}
+	if p.Redis != nil {
+		r.limiter = p.Redis
+	}
+	for _, cmd := range p.Commands {
+		r.Register(cmd)
+	}
+	return r
+}
+
+func (r *Registry) Register(cmd *Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands[cmd.Name] = cmd
+
+	if cmd.Handler != nil {
+		r.handlers[cmd.Name] = handlerEntry{handler: cmd.Handler, autocomplete: cmd.Autocomplete, middlewares: r.commandMiddlewares(cmd, nil)}
+	}
+	for _, sub := range cmd.Subcommands {
+		r.handlers[path(cmd.Name, sub.Name)] = handlerEntry{handler: sub.Handler, autocomplete: sub.Autocomplete, middlewares: r.commandMiddlewares(cmd, sub)}
+	}
+	for _, group := range cmd.Groups {
+		for _, sub := range group.Subcommands {
+			r.handlers[path(cmd.Name, group.Name, sub.Name)] = handlerEntry{handler: sub.Handler, autocomplete: sub.Autocomplete, middlewares: r.commandMiddlewares(cmd, sub)}
+		}
+	}
+}
 
-func Register(cmd *Command) {
-	mu.Lock()
-	defer mu.Unlock()
-	registry[cmd.Name] = cmd
+// commandMiddlewares lists the command's and subcommand's middlewares followed
+// by their cooldowns, so checks like GuildOnly reject before a cooldown is
+// spent. Cooldowns is installed even without declared limits so a COOLDOWNS
+// override can add some at runtime.
+func (r *Registry) commandMiddlewares(cmd *Command, sub *Subcommand) []Middleware {
+	mws := append([]Middleware{}, cmd.Middlewares...)
+	limits := append([]Cooldown{}, cmd.Cooldowns...)
+	if sub != nil {
+		mws = append(mws, sub.Middlewares...)
+		limits = append(limits, sub.Cooldowns...)
+	}
+	return append(mws, Cooldowns(r.limiter, limits...))
 }
 
-func Get(name string) (*Command, bool) {
-	mu.RLock()
-	defer mu.RUnlock()
-	cmd, ok := registry[name]
+func (r *Registry) Get(name string) (*Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cmd, ok := r.commands[name]
 	return cmd, ok
 }
 
-func GetAll() []*discordgo.ApplicationCommand {
-	mu.RLock()
-	defer mu.RUnlock()
-	cmds := make([]*discordgo.ApplicationCommand, 0, len(registry))
-	for _, cmd := range registry {
-		cmds = append(cmds, &discordgo.ApplicationCommand{
-			Name:        cmd.Name,
-			Description: cmd.Description,
-			Options:     cmd.Options,
-		})
+// Resolve walks the invoked command's subcommand group and subcommand options
+// to find the handler registered for the full path, wrapped in the global and
+// per-command middlewares.
+func (r *Registry) Resolve(data discordgo.ApplicationCommandInteractionData) (*Route, bool) {
+	segments, options := interaction.Walk(data)
+
+	r.mu.RLock()
+	entry, ok := r.handlers[path(segments...)]
+	r.mu.RUnlock()
+	if !ok || entry.handler == nil {
+		return nil, false
+	}
+
+	return &Route{
+		Path:    path(segments...),
+		Handler: Chain(entry.handler, append(r.global(), entry.middlewares...)...),
+		Options: options,
+	}, true
+}
+
+// ResolveAutocomplete finds the autocomplete handler for the invoked path.
+// Middlewares don't apply; autocomplete must answer within three seconds and
+// doesn't count against cooldowns. Panics are still recovered.
+func (r *Registry) ResolveAutocomplete(data discordgo.ApplicationCommandInteractionData) (CommandHandler, bool) {
+	segments, _ := interaction.Walk(data)
+
+	r.mu.RLock()
+	entry, ok := r.handlers[path(segments...)]
+	r.mu.RUnlock()
+	if !ok || entry.autocomplete == nil {
+		return nil, false
+	}
+	return func(ctx *interaction.Ctx) {
+		defer ctx.RecoverPanic()
+		entry.autocomplete(ctx)
+	}, true
+}
+
+// LeafOptions returns the options passed to the invoked (sub)command, skipping
+// over any subcommand group and subcommand wrappers.
+func LeafOptions(data discordgo.ApplicationCommandInteractionData) []*discordgo.ApplicationCommandInteractionDataOption {
+	_, options := interaction.Walk(data)
+	return options
+}
+
+// All returns the commands to register with Discord, with names and
+// descriptions translated from the i18n catalog.
+func (r *Registry) All() []*discordgo.ApplicationCommand {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cmds := make([]*discordgo.ApplicationCommand, 0, len(r.commands))
+	for _, cmd := range r.commands {
+		appCmd := &discordgo.ApplicationCommand{
+			Name:                     cmd.Name,
+			Description:              cmd.Description,
+			Options:                  cmd.options(),
+			DefaultMemberPermissions: cmd.DefaultMemberPermissions,
+			DMPermission:             cmd.DMPermission,
+		}
+		localizeCommand(r.catalog, appCmd)
+		cmds = append(cmds, appCmd)
 	}
+	sort.Slice(cmds, func(a, b int) bool {
+		return cmds[a].Name < cmds[b].Name
+	})
 	return cmds
 }
+
+func (cmd *Command) options() []*discordgo.ApplicationCommandOption {
+	if len(cmd.Subcommands) == 0 && len(cmd.Groups) == 0 {
+		return cmd.Options
+	}
+
+	options := make([]*discordgo.ApplicationCommandOption, 0, len(cmd.Groups)+len(cmd.Subcommands))
+	for _, group := range cmd.Groups {
+		subOptions := make([]*discordgo.ApplicationCommandOption, 0, len(group.Subcommands))
+		for _, sub := range group.Subcommands {
+			subOptions = append(subOptions, sub.option())
+		}
+		options = append(options, &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+			Name:        group.Name,
+			Description: group.Description,
+			Options:     subOptions,
+		})
+	}
+	for _, sub := range cmd.Subcommands {
+		options = append(options, sub.option())
+	}
+	return options
+}
+
+func (sub *Subcommand) option() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        sub.Name,
+		Description: sub.Description,
+		Options:     sub.Options,
+	}
+}
+
+func path(segments ...string) string {
+	return strings.Join(segments, " ")
+}
@@ -0,0 +1,30 @@
+package commands_test
+
+import (
+	"testing"
+
+	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/testkit"
+)
+
+func TestAutocompleteRecoversPanic(t *testing.T) {
+	bot := testkit.NewBot(t, nil)
+	bot.Commands = commands.NewRegistry(commands.RegistryParams{
+		Catalog: bot.Catalog,
+		Commands: []*commands.Command{{
+			Name:    "rank",
+			Handler: func(ctx *interaction.Ctx) {},
+			Autocomplete: func(ctx *interaction.Ctx) {
+				panic("autocomplete exploded")
+			},
+		}},
+	})
+
+	i := testkit.Autocomplete("rank").Focused("username", "ten").Build()
+	bot.Run(t, i)
+
+	if message := bot.Discord.Message(i); message != nil {
+		t.Errorf("autocomplete panic answered with %+v, want no response", message)
+	}
+}
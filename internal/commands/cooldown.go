@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/redisclient"
+)
+
+type CooldownScope string
+
+const (
+	CooldownUser    CooldownScope = "user"
+	CooldownGuild   CooldownScope = "guild"
+	CooldownChannel CooldownScope = "channel"
+)
+
+// Cooldown is a token bucket per user, guild or channel: Burst invocations
+// can be made back to back, after which one more is allowed every
+// Period/Burst, so over time it averages out to Burst per Period. A zero
+// Burst is treated as one.
+type Cooldown struct {
+	Scope  CooldownScope
+	Period time.Duration
+	Burst  int
+}
+
+// Limiter takes a token from each bucket, or from none when any is empty.
+// redisclient.Client is the implementation the bot uses.
+type Limiter interface {
+	TakeTokens(ctx context.Context, buckets []redisclient.Bucket) (bool, time.Duration, error)
+}
+
+// Cooldowns enforces limits through the limiter, which with Redis holds
+// across bot instances. All scopes are checked in one step, so a rejected
+// invocation spends none of them. Members with one of cfg.CooldownBypassRoles
+// skip the check entirely. If the limiter fails, or is nil, the command is
+// let through rather than blocked. The COOLDOWNS setting can replace the
+// declared limits at runtime, keyed by the full command path; with neither,
+// the middleware does nothing.
+func Cooldowns(limiter Limiter, declared ...Cooldown) Middleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx *interaction.Ctx) {
+			command := ctx.Command()
+			limits := declared
+			if overrides, ok := ctx.Config.Runtime().Cooldowns[command]; ok {
+				limits = make([]Cooldown, 0, len(overrides))
+				for _, limit := range overrides {
+					limits = append(limits, Cooldown{Scope: CooldownScope(limit.Scope), Period: limit.Period, Burst: limit.Burst})
+				}
+			}
+
+			if limiter == nil || len(limits) == 0 || bypassesCooldown(ctx) {
+				next(ctx)
+				return
+			}
+
+			buckets := make([]redisclient.Bucket, 0, len(limits))
+			for _, limit := range limits {
+				id := cooldownSubject(ctx, limit.Scope)
+				if id == "" {
+					continue
+				}
+				buckets = append(buckets, redisclient.Bucket{
+					Key:      fmt.Sprintf("cooldown:%s:%s:%s", command, limit.Scope, id),
+					Capacity: max(limit.Burst, 1),
+					Period:   limit.Period,
+				})
+			}
+
+			if len(buckets) == 0 {
+				next(ctx)
+				return
+			}
+
+			ok, retryAfter, err := limiter.TakeTokens(ctx, buckets)
+			if err != nil {
+				ctx.Log.Warn("Cooldown check failed, allowing command", "error", err)
+				next(ctx)
+				return
+			}
+
+			if !ok {
+				ctx.Log.Debug("Command on cooldown", "retry_after", retryAfter)
+				ctx.ReplyEphemeral(ctx.T("middleware.cooldown", i18n.Vars{
+					"command": command,
+					"when":    fmt.Sprintf("<t:%d:R>", time.Now().Add(retryAfter).Unix()),
+				}))
+				return
+			}
+
+			next(ctx)
+		}
+	}
+}
+
+func cooldownSubject(ctx *interaction.Ctx, scope CooldownScope) string {
+	switch scope {
+	case CooldownUser:
+		return ctx.UserID()
+	case CooldownGuild:
+		return ctx.Interaction.GuildID
+	case CooldownChannel:
+		return ctx.Interaction.ChannelID
+	default:
+		return ""
+	}
+}
+
+func bypassesCooldown(ctx *interaction.Ctx) bool {
+	if ctx.Interaction.Member == nil {
+		return false
+	}
+	for _, role := range ctx.Interaction.Member.Roles {
+		if slices.Contains(ctx.Config.CooldownBypassRoles, role) {
+			return true
+		}
+	}
+	return false
+}
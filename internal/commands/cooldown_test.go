@@ -0,0 +1,153 @@
+package commands_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/redisclient"
+	"yk-dc-bot/internal/testkit"
+)
+
+// limiter records the buckets it is asked for and answers with its fields.
+type limiter struct {
+	calls      [][]redisclient.Bucket
+	ok         bool
+	retryAfter time.Duration
+	err        error
+}
+
+func (l *limiter) TakeTokens(ctx context.Context, buckets []redisclient.Bucket) (bool, time.Duration, error) {
+	l.calls = append(l.calls, buckets)
+	return l.ok, l.retryAfter, l.err
+}
+
+var trackerCooldowns = []commands.Cooldown{
+	{Scope: commands.CooldownUser, Period: time.Minute, Burst: 2},
+	{Scope: commands.CooldownGuild, Period: time.Minute, Burst: 10},
+	{Scope: commands.CooldownChannel, Period: 30 * time.Second},
+}
+
+// runCooldowns runs the middleware in front of a handler that replies "ran",
+// and reports whether the handler was reached.
+func runCooldowns(t *testing.T, bot *testkit.Bot, l commands.Limiter, i *testkit.Interaction) bool {
+	t.Helper()
+	built := i.Build()
+	ctx := bot.Ctx(built)
+	defer ctx.Close()
+
+	ran := false
+	commands.Cooldowns(l, trackerCooldowns...)(func(ctx *interaction.Ctx) {
+		ran = true
+		ctx.Reply("ran")
+	})(ctx)
+	return ran
+}
+
+func TestCooldownsTakeEveryScopeAtOnce(t *testing.T) {
+	bot := testkit.NewBot(t, nil)
+	l := &limiter{ok: true}
+
+	if !runCooldowns(t, bot, l, testkit.Command("tracker")) {
+		t.Fatal("handler didn't run with tokens left")
+	}
+
+	if len(l.calls) != 1 {
+		t.Fatalf("limiter called %d times, want a single call for all scopes", len(l.calls))
+	}
+	want := []redisclient.Bucket{
+		{Key: "cooldown:tracker:user:" + testkit.UserID, Capacity: 2, Period: time.Minute},
+		{Key: "cooldown:tracker:guild:" + testkit.GuildID, Capacity: 10, Period: time.Minute},
+		{Key: "cooldown:tracker:channel:" + testkit.ChannelID, Capacity: 1, Period: 30 * time.Second},
+	}
+	got := l.calls[0]
+	if len(got) != len(want) {
+		t.Fatalf("buckets = %+v, want %+v", got, want)
+	}
+	for idx := range want {
+		if got[idx] != want[idx] {
+			t.Errorf("bucket %d = %+v, want %+v", idx, got[idx], want[idx])
+		}
+	}
+}
+
+func TestCooldownsRejectWhenAnyBucketIsEmpty(t *testing.T) {
+	bot := testkit.NewBot(t, nil)
+	l := &limiter{ok: false, retryAfter: 20 * time.Second}
+
+	i := testkit.Command("tracker")
+	if runCooldowns(t, bot, l, i) {
+		t.Fatal("handler ran although the limiter refused")
+	}
+	bot.Discord.AssertReply(t, i.Build(), "you can use /tracker again", true)
+}
+
+func TestCooldownsSkipGuildScopeInDMs(t *testing.T) {
+	bot := testkit.NewBot(t, nil)
+	l := &limiter{ok: true}
+
+	runCooldowns(t, bot, l, testkit.Command("tracker").InDM(testkit.UserID))
+
+	if got := l.calls[0]; len(got) != 2 || got[0].Key != "cooldown:tracker:user:"+testkit.UserID || got[1].Key != "cooldown:tracker:channel:"+testkit.ChannelID {
+		t.Errorf("buckets = %+v, want the user and channel scopes only", got)
+	}
+}
+
+func TestCooldownsBypassRoles(t *testing.T) {
+	bot := testkit.NewBot(t, &config.Config{CooldownBypassRoles: []string{"300000000000000001"}})
+	l := &limiter{ok: false, retryAfter: time.Minute}
+
+	if !runCooldowns(t, bot, l, testkit.Command("tracker").By(testkit.UserID, "300000000000000001")) {
+		t.Fatal("handler didn't run for a member with a bypass role")
+	}
+	if len(l.calls) != 0 {
+		t.Errorf("limiter called %d times for a bypassing member", len(l.calls))
+	}
+}
+
+func TestCooldownsFailOpen(t *testing.T) {
+	bot := testkit.NewBot(t, nil)
+
+	if !runCooldowns(t, bot, &limiter{err: errors.New("connection refused")}, testkit.Command("tracker")) {
+		t.Error("handler didn't run when the limiter failed")
+	}
+	if !runCooldowns(t, bot, nil, testkit.Command("tracker")) {
+		t.Error("handler didn't run without a limiter")
+	}
+}
+
+func TestCooldownsOverrideCommandWithoutLimits(t *testing.T) {
+	t.Setenv("COOLDOWNS", "profile=user:1/1m")
+	cfg, err := config.NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := testkit.NewBot(t, cfg)
+	l := &limiter{ok: true}
+
+	ctx := bot.Ctx(testkit.Command("profile").Build())
+	defer ctx.Close()
+	commands.Cooldowns(l)(func(ctx *interaction.Ctx) { ctx.Reply("ran") })(ctx)
+
+	if len(l.calls) != 1 || len(l.calls[0]) != 1 || l.calls[0][0].Key != "cooldown:profile:user:"+testkit.UserID {
+		t.Errorf("buckets = %+v, want the overridden user scope", l.calls)
+	}
+}
+
+func TestCooldownsWithoutLimitsDoNothing(t *testing.T) {
+	bot := testkit.NewBot(t, nil)
+	l := &limiter{ok: false}
+
+	ctx := bot.Ctx(testkit.Command("profile").Build())
+	defer ctx.Close()
+	ran := false
+	commands.Cooldowns(l)(func(ctx *interaction.Ctx) { ran = true })(ctx)
+
+	if !ran || len(l.calls) != 0 {
+		t.Errorf("ran = %v with %d limiter calls, want the handler run without asking the limiter", ran, len(l.calls))
+	}
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"yk-dc-bot/internal/i18n"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Command names and descriptions are translated from the i18n catalog, keyed
+// by their path: "commands.settings.name", "commands.settings.set.description",
+// "commands.rank.options.username.description" and, for choices,
+// "commands.heatmap.options.side.choices.attack". Option names and
+// descriptions shared by many commands can go under "options.<option>"
+// instead. The English text stays on the Command itself, and the shipped
+// catalogs leave top-level command names alone since replies refer to
+// commands by their English name.
+
+func localizeCommand(catalog *i18n.Catalog, cmd *discordgo.ApplicationCommand) {
+	key := "commands." + cmd.Name
+	cmd.NameLocalizations = optional(catalog.Localizations(key + ".name"))
+	cmd.DescriptionLocalizations = optional(catalog.Localizations(key + ".description"))
+	cmd.Options = localizeOptions(catalog, key, cmd.Options)
+}
+
+// localizeOptions returns translated copies, leaving the options declared by
+// the feature modules untouched.
+func localizeOptions(catalog *i18n.Catalog, prefix string, options []*discordgo.ApplicationCommandOption) []*discordgo.ApplicationCommandOption {
+	if len(options) == 0 {
+		return options
+	}
+
+	localized := make([]*discordgo.ApplicationCommandOption, 0, len(options))
+	for _, option := range options {
+		copied := *option
+		key := prefix + ".options." + option.Name
+		if option.Type == discordgo.ApplicationCommandOptionSubCommand || option.Type == discordgo.ApplicationCommandOptionSubCommandGroup {
+			key = prefix + "." + option.Name
+		}
+
+		copied.NameLocalizations = withShared(catalog, key, option.Name, "name")
+		copied.DescriptionLocalizations = withShared(catalog, key, option.Name, "description")
+		copied.Options = localizeOptions(catalog, key, option.Options)
+
+		if len(option.Choices) > 0 {
+			copied.Choices = make([]*discordgo.ApplicationCommandOptionChoice, 0, len(option.Choices))
+			for _, choice := range option.Choices {
+				c := *choice
+				c.NameLocalizations = catalog.Localizations(key + ".choices." + choice.Name)
+				copied.Choices = append(copied.Choices, &c)
+			}
+		}
+		localized = append(localized, &copied)
+	}
+	return localized
+}
+
+// withShared looks up field under key, filling in locales it lacks from the
+// shared "options.<option>" entry.
+func withShared(catalog *i18n.Catalog, key, option, field string) map[discordgo.Locale]string {
+	localizations := catalog.Localizations("options." + option + "." + field)
+	specific := catalog.Localizations(key + "." + field)
+	if localizations == nil {
+		return specific
+	}
+	for locale, text := range specific {
+		localizations[locale] = text
+	}
+	return localizations
+}
+
+func optional(localizations map[discordgo.Locale]string) *map[discordgo.Locale]string {
+	if localizations == nil {
+		return nil
+	}
+	return &localizations
+}
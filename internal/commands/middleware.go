@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/guildsettings"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/metrics"
+)
+
+// Middleware wraps a CommandHandler. Middlewares run in the order they are
+// listed: the first one is the outermost.
+type Middleware func(next CommandHandler) CommandHandler
+
+// Use adds middlewares that run around every command, outside any
+// per-command middlewares.
+func (r *Registry) Use(mws ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middlewares = append(r.middlewares, mws...)
+}
+
+func Chain(handler CommandHandler, mws ...Middleware) CommandHandler {
+	for idx := len(mws) - 1; idx >= 0; idx-- {
+		handler = mws[idx](handler)
+	}
+	return handler
+}
+
+func (r *Registry) global() []Middleware {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	mws := make([]Middleware, len(r.middlewares))
+	copy(mws, r.middlewares)
+	return mws
+}
+
+// Recover turns a handler panic into a logged error and an error embed so a
+// single broken handler can't take the gateway event goroutine down with it.
+func Recover() Middleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx *interaction.Ctx) {
+			defer ctx.RecoverPanic()
+			next(ctx)
+		}
+	}
+}
+
+// Logging records who ran which command, where, and how long it took.
+func Logging() Middleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx *interaction.Ctx) {
+			start := time.Now()
+			defer func() {
+				r := recover()
+				outcome := outcomeOf(ctx, r)
+				ctx.Log.Info("Command handled",
+					"channel", ctx.Interaction.ChannelID,
+					"duration", time.Since(start),
+					"outcome", outcome,
+				)
+				if r != nil {
+					panic(r)
+				}
+			}()
+			next(ctx)
+		}
+	}
+}
+
+// Metrics counts and times every command by outcome.
+func Metrics(m *metrics.Bot) Middleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx *interaction.Ctx) {
+			start := time.Now()
+			defer func() {
+				r := recover()
+				outcome := outcomeOf(ctx, r)
+				m.ObserveCommand(ctx.Command(), outcome, start)
+				if r != nil {
+					panic(r)
+				}
+			}()
+			next(ctx)
+		}
+	}
+}
+
+func outcomeOf(ctx *interaction.Ctx, recovered any) string {
+	switch {
+	case recovered != nil:
+		return "panic"
+	case ctx.Failed():
+		return "error"
+	default:
+		return "ok"
+	}
+}
+
+// Check rejects the invocation with an ephemeral message when check returns an
+// error. AppError user messages are shown as-is.
+func Check(check func(ctx *interaction.Ctx) error) Middleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx *interaction.Ctx) {
+			if err := check(ctx); err != nil {
+				errorMessage, logMessage := ctx.ErrorMessage(err, "checking "+ctx.Command())
+				ctx.Log.Debug(logMessage)
+				ctx.ReplyEphemeral(errorMessage)
+				return
+			}
+			next(ctx)
+		}
+	}
+}
+
+// OwnerOnly restricts a command to the users listed in BOT_OWNERS.
+func OwnerOnly(cfg *config.Config) Middleware {
+	return Check(func(ctx *interaction.Ctx) error {
+		if !cfg.IsOwner(ctx.UserID()) {
+			return apperrors.New("COMMAND_FORBIDDEN", "user is not a bot owner", "this command is only for the bot's owners")
+		}
+		return nil
+	})
+}
+
+func GuildOnly() Middleware {
+	return Check(func(ctx *interaction.Ctx) error {
+		if ctx.Interaction.GuildID == "" {
+			return apperrors.New("COMMAND_GUILD_ONLY", "command used outside a guild", "this command only works in a server")
+		}
+		return nil
+	})
+}
+
+func RequirePermissions(permissions int64) Middleware {
+	return Check(func(ctx *interaction.Ctx) error {
+		member := ctx.Interaction.Member
+		if member == nil || member.Permissions&permissions != permissions {
+			return apperrors.New("COMMAND_FORBIDDEN", fmt.Sprintf("member lacks permissions %d", permissions), "you don't have permission to use this command")
+		}
+		return nil
+	})
+}
+
+// GuildSettings loads the guild's settings into ctx.Guild and rejects commands
+// the guild has disabled. If the settings can't be loaded the defaults apply.
+func GuildSettings(store *guildsettings.Store) Middleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx *interaction.Ctx) {
+			settings, err := store.Get(ctx, ctx.Interaction.GuildID)
+			if err != nil {
+				ctx.Log.Warn("Failed to load guild settings, using defaults", "error", err)
+			}
+			ctx.Guild = settings
+
+			if !settings.CommandEnabled(ctx.Command()) {
+				ctx.ReplyEphemeral(ctx.T("middleware.disabled", i18n.Vars{"command": ctx.Command()}))
+				return
+			}
+			next(ctx)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type ChangeKind string
+
+const (
+	ChangeCreate    ChangeKind = "create"
+	ChangeUpdate    ChangeKind = "update"
+	ChangeDelete    ChangeKind = "delete"
+	ChangeKeep      ChangeKind = "keep"
+	ChangeUnchanged ChangeKind = "unchanged"
+)
+
+type Change struct {
+	Kind    ChangeKind
+	Name    string
+	Command *discordgo.ApplicationCommand
+}
+
+type Plan struct {
+	Changes []Change
+}
+
+// Diff compares the commands registered with Discord against the local set.
+// Stale commands are marked for deletion when prune is set and kept otherwise.
+func Diff(existing, desired []*discordgo.ApplicationCommand, prune bool) *Plan {
+	existingByName := make(map[string]*discordgo.ApplicationCommand, len(existing))
+	for _, cmd := range existing {
+		existingByName[cmd.Name] = cmd
+	}
+
+	plan := &Plan{}
+	seen := make(map[string]bool, len(desired))
+	for _, cmd := range desired {
+		seen[cmd.Name] = true
+		current, ok := existingByName[cmd.Name]
+		switch {
+		case !ok:
+			plan.Changes = append(plan.Changes, Change{Kind: ChangeCreate, Name: cmd.Name, Command: cmd})
+		case !Equal(current, cmd):
+			plan.Changes = append(plan.Changes, Change{Kind: ChangeUpdate, Name: cmd.Name, Command: cmd})
+		default:
+			plan.Changes = append(plan.Changes, Change{Kind: ChangeUnchanged, Name: cmd.Name, Command: cmd})
+		}
+	}
+
+	for _, cmd := range existing {
+		if seen[cmd.Name] {
+			continue
+		}
+		kind := ChangeKeep
+		if prune {
+			kind = ChangeDelete
+		}
+		plan.Changes = append(plan.Changes, Change{Kind: kind, Name: cmd.Name, Command: cmd})
+	}
+
+	sort.SliceStable(plan.Changes, func(a, b int) bool {
+		return plan.Changes[a].Name < plan.Changes[b].Name
+	})
+	return plan
+}
+
+func (p *Plan) HasChanges() bool {
+	for _, change := range p.Changes {
+		if change.Kind == ChangeCreate || change.Kind == ChangeUpdate || change.Kind == ChangeDelete {
+			return true
+		}
+	}
+	return false
+}
+
+// Commands returns the full command set to send in a bulk overwrite.
+func (p *Plan) Commands() []*discordgo.ApplicationCommand {
+	cmds := make([]*discordgo.ApplicationCommand, 0, len(p.Changes))
+	for _, change := range p.Changes {
+		if change.Kind == ChangeDelete {
+			continue
+		}
+		cmds = append(cmds, normalize(change.Command))
+	}
+	return cmds
+}
+
+func (p *Plan) Count(kind ChangeKind) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// Equal reports whether two commands would register identically, ignoring
+// server-assigned fields and defaults Discord fills in on its side.
+func Equal(a, b *discordgo.ApplicationCommand) bool {
+	aj, errA := json.Marshal(normalize(a))
+	bj, errB := json.Marshal(normalize(b))
+	if errA != nil || errB != nil {
+		return false
+	}
+
+	var av, bv interface{}
+	_ = json.Unmarshal(aj, &av)
+	_ = json.Unmarshal(bj, &bv)
+	return reflect.DeepEqual(av, bv)
+}
+
+func normalize(cmd *discordgo.ApplicationCommand) *discordgo.ApplicationCommand {
+	dmPermission := true
+	if cmd.DMPermission != nil {
+		dmPermission = *cmd.DMPermission
+	}
+	nsfw := false
+	if cmd.NSFW != nil {
+		nsfw = *cmd.NSFW
+	}
+	cmdType := cmd.Type
+	if cmdType == 0 {
+		cmdType = discordgo.ChatApplicationCommand
+	}
+
+	return &discordgo.ApplicationCommand{
+		Type:                     cmdType,
+		Name:                     cmd.Name,
+		NameLocalizations:        nonEmptyLocalizations(cmd.NameLocalizations),
+		DefaultMemberPermissions: cmd.DefaultMemberPermissions,
+		DMPermission:             &dmPermission,
+		NSFW:                     &nsfw,
+		Description:              cmd.Description,
+		DescriptionLocalizations: nonEmptyLocalizations(cmd.DescriptionLocalizations),
+		Options:                  normalizeOptions(cmd.Options),
+	}
+}
+
+func normalizeOptions(options []*discordgo.ApplicationCommandOption) []*discordgo.ApplicationCommandOption {
+	if len(options) == 0 {
+		return nil
+	}
+
+	normalized := make([]*discordgo.ApplicationCommandOption, 0, len(options))
+	for _, option := range options {
+		copied := *option
+		copied.Options = normalizeOptions(option.Options)
+		if len(copied.Choices) == 0 {
+			copied.Choices = nil
+		}
+		if len(copied.ChannelTypes) == 0 {
+			copied.ChannelTypes = nil
+		}
+		normalized = append(normalized, &copied)
+	}
+	return normalized
+}
+
+func nonEmptyLocalizations(l *map[discordgo.Locale]string) *map[discordgo.Locale]string {
+	if l == nil || len(*l) == 0 {
+		return nil
+	}
+	return l
+}
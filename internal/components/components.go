@@ -0,0 +1,395 @@
+package components
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/guildsettings"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/logger"
+	redisclient "yk-dc-bot/internal/redisclient"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/fx"
+)
+
+// maxCustomIDLength is Discord's limit for component and modal custom IDs.
+const maxCustomIDLength = 100
+
+const (
+	separator   = ":"
+	spillMarker = "~"
+)
+
+type Handler func(ctx *interaction.Ctx, args Args)
+
+// Route binds a custom ID pattern such as "rank:refresh:{name}:{tag}" to a
+// handler. Literal segments must match exactly; {params} are extracted into
+// Args.
+type Route struct {
+	Pattern string
+	Handler Handler
+
+	// Signed routes carry an HMAC so users can't forge IDs; TTL additionally
+	// embeds an expiry. Either one appends "<expiry>:<signature>" to the ID.
+	Signed bool
+	TTL    time.Duration
+
+	// Permissions are the member permissions required to use the component.
+	Permissions int64
+	// Check is an optional extra guard, e.g. restricting a button to the user
+	// who ran the original command.
+	Check func(i *discordgo.InteractionCreate, args Args) bool
+
+	segments []segment
+	compile  sync.Once
+}
+
+type segment struct {
+	literal string
+	param   string
+}
+
+type Args map[string]string
+
+func (a Args) String(name string) string {
+	return a[name]
+}
+
+func (a Args) Int(name string) (int64, error) {
+	value, err := strconv.ParseInt(a[name], 10, 64)
+	if err != nil {
+		return 0, apperrors.Wrap(err, "COMPONENT_ARG_ERROR", fmt.Sprintf("component argument %s is not an integer", name))
+	}
+	return value, nil
+}
+
+func (a Args) Bool(name string) bool {
+	value, _ := strconv.ParseBool(a[name])
+	return value
+}
+
+func parsePattern(pattern string) []segment {
+	parts := strings.Split(pattern, separator)
+	segments := make([]segment, 0, len(parts))
+	for _, part := range parts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			segments = append(segments, segment{param: part[1 : len(part)-1]})
+		} else {
+			segments = append(segments, segment{literal: part})
+		}
+	}
+	return segments
+}
+
+func (r *Route) parsed() []segment {
+	r.compile.Do(func() {
+		r.segments = parsePattern(r.Pattern)
+	})
+	return r.segments
+}
+
+func (r *Route) secured() bool {
+	return r.Signed || r.TTL > 0
+}
+
+// prefix is the run of literal segments before the first parameter.
+func (r *Route) prefix() []string {
+	var literals []string
+	for _, seg := range r.parsed() {
+		if seg.param != "" {
+			break
+		}
+		literals = append(literals, seg.literal)
+	}
+	return literals
+}
+
+// Codec builds and verifies custom IDs. Features use it to create their
+// buttons; the Router uses it to decode them again.
+type Codec struct {
+	secret      []byte
+	redisClient *redisclient.Client
+	log         *logger.Logger
+}
+
+func NewCodec(cfg *config.Config, redisClient *redisclient.Client, log *logger.Logger) *Codec {
+	secret := []byte(cfg.ComponentSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		_, _ = rand.Read(secret)
+		log.Warn("COMPONENT_SIGNING_SECRET is not set, signed components won't survive a restart")
+	}
+
+	return &Codec{
+		secret:      secret,
+		redisClient: redisClient,
+		log:         log,
+	}
+}
+
+// CustomID builds a custom ID for route, filling its {params} in order. IDs
+// that would exceed Discord's length limit have their arguments stored in
+// Redis behind a short random token instead.
+func (c *Codec) CustomID(route *Route, args ...string) (string, error) {
+	segments := route.parsed()
+	parts := make([]string, 0, len(segments)+2)
+	values := make(Args)
+	argIdx := 0
+	for _, seg := range segments {
+		if seg.param == "" {
+			parts = append(parts, seg.literal)
+			continue
+		}
+		if argIdx >= len(args) {
+			return "", apperrors.New("COMPONENT_ROUTE_ERROR", fmt.Sprintf("missing argument %s for %s", seg.param, route.Pattern))
+		}
+		values[seg.param] = args[argIdx]
+		parts = append(parts, escape(args[argIdx]))
+		argIdx++
+	}
+
+	if route.secured() {
+		expiry := "0"
+		if route.TTL > 0 {
+			expiry = strconv.FormatInt(time.Now().Add(route.TTL).Unix(), 36)
+		}
+		parts = append(parts, expiry)
+		parts = append(parts, c.sign(strings.Join(parts, separator)))
+	}
+
+	customID := strings.Join(parts, separator)
+	if len(customID) <= maxCustomIDLength {
+		return customID, nil
+	}
+
+	return c.spill(route, values)
+}
+
+type spilledPayload struct {
+	Pattern string `json:"pattern"`
+	Args    Args   `json:"args"`
+}
+
+func (c *Codec) spill(route *Route, args Args) (string, error) {
+	tokenBytes := make([]byte, 12)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", apperrors.Wrap(err, "COMPONENT_SPILL_ERROR", "failed to generate component token")
+	}
+	token := base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	ttl := route.TTL
+	if ttl == 0 {
+		ttl = 24 * time.Hour
+	}
+
+	payload, _ := json.Marshal(spilledPayload{Pattern: route.Pattern, Args: args})
+	if err := c.redisClient.Set(context.Background(), spillKey(token), string(payload), ttl); err != nil {
+		return "", apperrors.Wrap(err, "COMPONENT_SPILL_ERROR", "failed to store component payload")
+	}
+
+	customID := strings.Join(append(route.prefix(), spillMarker+token), separator)
+	if len(customID) > maxCustomIDLength {
+		return "", apperrors.New("COMPONENT_ROUTE_ERROR", fmt.Sprintf("pattern %s is too long for a custom ID", route.Pattern))
+	}
+	return customID, nil
+}
+
+func (c *Codec) sign(payload string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:8])
+}
+
+var (
+	errExpired   = apperrors.New("COMPONENT_EXPIRED", "component custom ID has expired", "this button has expired, run the command again")
+	errForged    = apperrors.New("COMPONENT_FORGED", "component custom ID signature mismatch", "this button isn't valid anymore")
+	errForbidden = apperrors.New("COMPONENT_FORBIDDEN", "member is not allowed to use this component", "you can't use this")
+)
+
+// Router dispatches component and modal interactions to the routes provided by
+// the enabled feature modules.
+type Router struct {
+	codec  *Codec
+	routes []*Route
+	guilds *guildsettings.Store
+}
+
+type RouterParams struct {
+	fx.In
+
+	Codec  *Codec
+	Routes []*Route `group:"components"`
+	// Guilds loads the guild's settings before a route runs, so its replies
+	// use the same language and visibility as the command that sent the
+	// component.
+	Guilds *guildsettings.Store `optional:"true"`
+}
+
+func NewRouter(p RouterParams) *Router {
+	return &Router{codec: p.Codec, routes: p.Routes, guilds: p.Guilds}
+}
+
+// Match resolves a custom ID to its route and arguments, verifying signatures
+// and expiry. It returns ok=false when no route matches at all.
+func (r *Router) Match(customID string) (*Route, Args, bool, error) {
+	parts := strings.Split(customID, separator)
+	for _, route := range r.routes {
+		if args, ok, err := r.codec.matchSpilled(route, parts); ok {
+			return route, args, true, err
+		}
+		if args, ok, err := r.codec.matchInline(route, parts); ok {
+			return route, args, true, err
+		}
+	}
+	return nil, nil, false, nil
+}
+
+func (c *Codec) matchSpilled(route *Route, parts []string) (Args, bool, error) {
+	prefix := route.prefix()
+	if len(parts) != len(prefix)+1 || !strings.HasPrefix(parts[len(parts)-1], spillMarker) {
+		return nil, false, nil
+	}
+	for idx, literal := range prefix {
+		if parts[idx] != literal {
+			return nil, false, nil
+		}
+	}
+
+	token := strings.TrimPrefix(parts[len(parts)-1], spillMarker)
+	data, err := c.redisClient.Get(context.Background(), spillKey(token))
+	if err != nil {
+		return nil, true, errExpired
+	}
+
+	var payload spilledPayload
+	if err := json.Unmarshal([]byte(data), &payload); err != nil || payload.Pattern != route.Pattern {
+		// a different route sharing the same literal prefix may own the token
+		return nil, false, nil
+	}
+	return payload.Args, true, nil
+}
+
+func (c *Codec) matchInline(route *Route, parts []string) (Args, bool, error) {
+	segments := route.parsed()
+	expected := len(segments)
+	if route.secured() {
+		expected += 2
+	}
+	if len(parts) != expected {
+		return nil, false, nil
+	}
+
+	args := make(Args)
+	for idx, seg := range segments {
+		if seg.param == "" {
+			if parts[idx] != seg.literal {
+				return nil, false, nil
+			}
+			continue
+		}
+		args[seg.param] = unescape(parts[idx])
+	}
+
+	if !route.secured() {
+		return args, true, nil
+	}
+
+	signed := strings.Join(parts[:len(parts)-1], separator)
+	if !hmac.Equal([]byte(c.sign(signed)), []byte(parts[len(parts)-1])) {
+		return nil, true, errForged
+	}
+
+	expiry, err := strconv.ParseInt(parts[len(parts)-2], 36, 64)
+	if err != nil {
+		return nil, true, errForged
+	}
+	if expiry != 0 && time.Now().Unix() > expiry {
+		return nil, true, errExpired
+	}
+
+	return args, true, nil
+}
+
+// Dispatch routes a message component or modal submit interaction. It returns
+// false when no route matches the custom ID. The guild's settings are loaded
+// first, as commands.GuildSettings does for commands, and a panicking handler
+// is recovered the way commands.Recover does.
+func (r *Router) Dispatch(ctx *interaction.Ctx) bool {
+	i := ctx.Interaction
+	var customID string
+	switch i.Type {
+	case discordgo.InteractionMessageComponent:
+		customID = i.MessageComponentData().CustomID
+	case discordgo.InteractionModalSubmit:
+		customID = i.ModalSubmitData().CustomID
+	default:
+		return false
+	}
+
+	route, args, ok, err := r.Match(customID)
+	if !ok {
+		return false
+	}
+
+	if r.guilds != nil {
+		settings, loadErr := r.guilds.Get(ctx, i.GuildID)
+		if loadErr != nil {
+			ctx.Log.Warn("Failed to load guild settings, using defaults", "error", loadErr)
+		}
+		ctx.Guild = settings
+	}
+
+	if err == nil && !allowed(route, i, args) {
+		err = errForbidden
+	}
+
+	if err != nil {
+		errorMessage, logMessage := ctx.ErrorMessage(err, "routing component "+route.Pattern)
+		ctx.Log.Warn(logMessage)
+		_ = ctx.ReplyEphemeral(errorMessage)
+		return true
+	}
+
+	func() {
+		defer ctx.RecoverPanic()
+		route.Handler(ctx, args)
+	}()
+	return true
+}
+
+func allowed(route *Route, i *discordgo.InteractionCreate, args Args) bool {
+	if route.Permissions != 0 {
+		if i.Member == nil || i.Member.Permissions&route.Permissions != route.Permissions {
+			return false
+		}
+	}
+	if route.Check != nil && !route.Check(i, args) {
+		return false
+	}
+	return true
+}
+
+func spillKey(token string) string {
+	return "component:" + token
+}
+
+// escape keeps argument values from colliding with the segment separator.
+func escape(value string) string {
+	return strings.NewReplacer("%", "%25", separator, "%3A").Replace(value)
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer("%3A", separator, "%25", "%").Replace(value)
+}
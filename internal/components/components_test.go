@@ -0,0 +1,56 @@
+package components_test
+
+import (
+	"testing"
+
+	"yk-dc-bot/internal/components"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/guildsettings"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/testkit"
+)
+
+func TestDispatchRecoversPanickingHandler(t *testing.T) {
+	bot := testkit.NewBot(t, nil)
+	bot.Router = components.NewRouter(components.RouterParams{
+		Codec: components.NewCodec(&config.Config{ComponentSecret: "test"}, nil, bot.Log),
+		Routes: []*components.Route{{
+			Pattern: "boom:{id}",
+			Handler: func(ctx *interaction.Ctx, args components.Args) {
+				panic("component handler exploded")
+			},
+		}},
+	})
+
+	i := testkit.Component("boom:1").Build()
+	bot.Run(t, i)
+
+	bot.Discord.AssertError(t, i, "An unexpected error occurred")
+	if !bot.Discord.Ephemeral(i) {
+		t.Error("error reply isn't ephemeral")
+	}
+}
+
+func TestDispatchAppliesGuildSettings(t *testing.T) {
+	bot := testkit.NewBot(t, nil)
+	redis := testkit.NewRedis(t)
+	redis.Set("guild_settings:"+testkit.GuildID, `{"ephemeral":"true"}`)
+
+	bot.Router = components.NewRouter(components.RouterParams{
+		Codec:  components.NewCodec(&config.Config{ComponentSecret: "test"}, nil, bot.Log),
+		Guilds: guildsettings.NewStore(nil, redis.Client(t), bot.Catalog, bot.Log),
+		Routes: []*components.Route{{
+			Pattern: "page:{n}",
+			Handler: func(ctx *interaction.Ctx, args components.Args) {
+				ctx.Defer("loading")
+			},
+		}},
+	})
+
+	i := testkit.Component("page:2").Build()
+	bot.Run(t, i)
+
+	if !bot.Discord.Ephemeral(i) {
+		t.Error("button reply ignored the guild's ephemeral setting")
+	}
+}
@@ -1,6 +1,19 @@
 package config
 
 import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"yk-dc-bot/internal/apperrors"
+
 	"github.com/spf13/viper"
 )
 
@@ -9,6 +22,33 @@ type Config struct {
 	DB              DBConfig
 	Redis           RedisConfig
 	HdevApiKey      string
+	APIs            APIConfig
+	Fixtures        FixturesConfig
+	Log             LogConfig
+	// SchemaStrict turns provider schema drift into request errors instead
+	// of warnings.
+	SchemaStrict    bool
+	ComponentSecret string
+	// CooldownBypassRoles are role IDs whose members skip command cooldowns.
+	CooldownBypassRoles []string
+	// DisabledFeatures are handler modules (named after their command) that
+	// should not be registered.
+	DisabledFeatures []string
+	// Owners are user IDs allowed to run owner commands such as /errors.
+	Owners       []string
+	ErrorReports ErrorReportConfig
+	// MetricsAddr is where /metrics, /healthz and /readyz are served; empty
+	// disables them.
+	MetricsAddr string
+	Tracing     TracingConfig
+	// ShutdownTimeout is how long shutdown waits for running interactions
+	// before telling their users the bot is restarting.
+	ShutdownTimeout time.Duration
+
+	runtime  atomic.Pointer[Runtime]
+	values   map[string]string
+	sources  map[string]string
+	problems []string
 }
 
 type DBConfig struct {
@@ -25,6 +65,58 @@ type RedisConfig struct {
 	Password string
 }
 
+// APIConfig points the API clients at their upstreams; override them to use
+// cmd/fakeapi or another stand-in.
+type APIConfig struct {
+	HenrikBaseURL   string
+	TrackerBaseURL  string
+	ValorantBaseURL string
+}
+
+// FixturesConfig controls HTTP fixtures for the API clients. Mode is "record"
+// to save real responses under Dir, "replay" to serve only saved responses,
+// or empty to do neither.
+type FixturesConfig struct {
+	Mode string
+	Dir  string
+}
+
+// LogConfig controls how log entries are written. Format is "text", "json"
+// or "logfmt"; File, if set, receives a copy of every entry and is rotated
+// once it reaches MaxSizeMB.
+type LogConfig struct {
+	Format     string
+	File       string
+	MaxSizeMB  int
+	MaxBackups int
+}
+
+// ErrorReportConfig controls where failures are posted. Reports go to
+// Webhook if set, otherwise to ChannelID; with neither, occurrences are only
+// stored. Groups are flushed every Interval, the same error is posted at most
+// once per Cooldown, and at most HourlyLimit reports go out per hour.
+type ErrorReportConfig struct {
+	Webhook     string
+	ChannelID   string
+	Interval    time.Duration
+	Cooldown    time.Duration
+	HourlyLimit int
+	// Ignore lists error codes that are expected, such as unknown players,
+	// and are neither posted nor stored.
+	Ignore []string
+}
+
+// TracingConfig controls where spans are exported. Exporter is "none",
+// "stdout" for JSON lines on stdout, or "otlp" to post them to an
+// OTLP/HTTP collector at Endpoint. SampleRate is the share of interactions
+// that are traced.
+type TracingConfig struct {
+	Exporter    string
+	Endpoint    string
+	ServiceName string
+	SampleRate  float64
+}
+
 type Option func(*Config)
 
 func WithDiscordBotToken(token string) Option {
@@ -39,11 +131,185 @@ func WithDBConfig(dbConfig DBConfig) Option {
 	}
 }
 
+// setting is a single configuration key. Keys are the environment variable
+// names; config files use the same names (any case) and flags the lowercased,
+// dashed form, e.g. --discord-bot-token.
+type setting struct {
+	key        string
+	def        string
+	secret     bool
+	reloadable bool
+	usage      string
+}
+
+var settings = []setting{
+	{key: "DISCORD_BOT_TOKEN", secret: true, usage: "Discord bot token"},
+	{key: "DB_HOST", def: "localhost", usage: "Postgres host"},
+	{key: "DB_PORT", def: "5432", usage: "Postgres port"},
+	{key: "DB_USER", usage: "Postgres user"},
+	{key: "DB_PASSWORD", secret: true, usage: "Postgres password"},
+	{key: "DB_NAME", usage: "Postgres database"},
+	{key: "REDIS_HOST", def: "localhost", usage: "Redis host"},
+	{key: "REDIS_PORT", def: "6379", usage: "Redis port"},
+	{key: "REDIS_PASSWORD", secret: true, usage: "Redis password"},
+	{key: "HENRIKDEV_API_KEY", secret: true, usage: "HenrikDev API key"},
+	{key: "HENRIKDEV_BASE_URL", def: "https://api.henrikdev.xyz/valorant", usage: "HenrikDev API base URL"},
+	{key: "TRACKER_BASE_URL", def: "https://api.tracker.gg/api/v2/valorant/standard/profile/riot", usage: "tracker.gg profile API base URL"},
+	{key: "VALORANT_API_BASE_URL", def: "https://valorant-api.com/v1", usage: "valorant-api.com base URL"},
+	{key: "FIXTURES_MODE", usage: "record or replay HTTP fixtures"},
+	{key: "FIXTURES_DIR", def: "testdata/fixtures", usage: "directory for HTTP fixtures"},
+	{key: "SCHEMA_STRICT", def: "false", usage: "fail requests whose responses drift from the expected schema"},
+	{key: "COMPONENT_SIGNING_SECRET", secret: true, usage: "secret for signing component custom IDs"},
+	{key: "COOLDOWN_BYPASS_ROLES", usage: "comma-separated role IDs that bypass cooldowns"},
+	{key: "DISABLED_FEATURES", usage: "comma-separated features to leave out"},
+	{key: "BOT_OWNERS", usage: "comma-separated user IDs allowed to run owner commands"},
+	{key: "ERROR_REPORT_WEBHOOK", secret: true, usage: "Discord webhook URL that receives error reports"},
+	{key: "ERROR_REPORT_CHANNEL", usage: "channel ID that receives error reports when no webhook is set"},
+	{key: "ERROR_REPORT_INTERVAL", def: "1m", usage: "how often grouped error reports are posted"},
+	{key: "ERROR_REPORT_COOLDOWN", def: "15m", usage: "how long the same error is held back after being posted"},
+	{key: "ERROR_REPORT_LIMIT", def: "10", usage: "maximum error reports posted per hour"},
+	{key: "ERROR_REPORT_IGNORE", def: "MATCH_HISTORY_EMPTY,TRACKER_NOT_FOUND,TRACKER_RATE_LIMITED,MAP_NOT_FOUND,RENDER_NO_DATA,HEATMAP_NO_DATA,API_STATUS_ERROR_404,UPSTREAM_UNAVAILABLE", usage: "comma-separated error codes that are not reported"},
+	{key: "METRICS_ADDR", def: ":9090", usage: "listen address for /metrics, /healthz and /readyz, empty to disable"},
+	{key: "SHUTDOWN_TIMEOUT", def: "20s", usage: "how long shutdown waits for running interactions to finish"},
+	{key: "TRACE_EXPORTER", def: "none", usage: "none, stdout or otlp"},
+	{key: "TRACE_OTLP_ENDPOINT", def: "http://localhost:4318", usage: "OTLP/HTTP collector that receives spans when TRACE_EXPORTER is otlp"},
+	{key: "TRACE_SERVICE_NAME", def: "yk-dc-bot", usage: "service.name reported with every span"},
+	{key: "TRACE_SAMPLE_RATE", def: "1", usage: "share of interactions that are traced, from 0 to 1"},
+	{key: "LOG_FORMAT", def: "text", usage: "text, json or logfmt"},
+	{key: "LOG_FILE", usage: "also write logs to this file, rotating it by size"},
+	{key: "LOG_MAX_SIZE_MB", def: "100", usage: "size in MB at which LOG_FILE is rotated"},
+	{key: "LOG_MAX_BACKUPS", def: "5", usage: "how many rotated log files to keep"},
+
+	{key: "LOG_LEVEL", def: "info", reloadable: true, usage: "debug, info, warn or error"},
+	{key: "CACHE_TTL_ACCOUNT", def: "12h", reloadable: true, usage: "how long Riot ID lookups are cached"},
+	{key: "CACHE_TTL_DETAILED_ACCOUNT", def: "4h", reloadable: true, usage: "how long account details are cached"},
+	{key: "CACHE_TTL_MMR", def: "1m", reloadable: true, usage: "how long MMR is cached"},
+	{key: "CACHE_TTL_MATCHES", def: "5m", reloadable: true, usage: "how long match history is cached"},
+	{key: "CACHE_TTL_MATCH", def: "24h", reloadable: true, usage: "how long match details are cached"},
+	{key: "CACHE_TTL_TRACKER", def: "30m", reloadable: true, usage: "how long tracker.gg profiles are cached"},
+	{key: "COOLDOWNS", reloadable: true, usage: "per-command cooldown overrides, e.g. tracker=user:2/1m,guild:10/1m;heatmap=user:3/30s"},
+	{key: "TRACKER_PROXIES", secret: true, reloadable: true, usage: "comma-separated proxy URLs to rotate through when tracker.gg rate limits"},
+}
+
+func (s setting) flag() string {
+	return strings.ToLower(strings.ReplaceAll(s.key, "_", "-"))
+}
+
+// Loader resolves the configuration from, in increasing precedence: defaults,
+// a YAML or TOML file, a .env file, the environment, KEY_FILE secret files
+// and command-line flags.
+type Loader struct {
+	flags *flag.FlagSet
+	file  string
+}
+
+// NewLoader registers --config and a flag per setting on fs. The flags are
+// only read after fs has been parsed, so create the loader before fs.Parse.
+func NewLoader(fs *flag.FlagSet) *Loader {
+	l := &Loader{flags: fs}
+	fs.StringVar(&l.file, "config", "", "YAML or TOML config file (default $CONFIG_FILE)")
+	for _, s := range settings {
+		fs.String(s.flag(), "", s.usage)
+	}
+	return l
+}
+
+// NewConfig loads the configuration without command-line flags.
 func NewConfig(opts ...Option) (*Config, error) {
+	return (&Loader{}).Load(opts...)
+}
+
+func (l *Loader) Load(opts ...Option) (*Config, error) {
 	v := viper.New()
-	v.SetConfigFile(".env")
-	if err := v.ReadInConfig(); err != nil {
-		return nil, err
+	sources := make(map[string]string, len(settings))
+	for _, s := range settings {
+		v.SetDefault(s.key, s.def)
+		sources[s.key] = "default"
+	}
+
+	if file := l.configFile(); file != "" {
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, apperrors.Wrap(err, "CONFIG_FILE_ERROR", fmt.Sprintf("failed to read config file %s", file))
+		}
+		for _, s := range settings {
+			if v.InConfig(s.key) {
+				sources[s.key] = file
+			}
+		}
+	}
+
+	// .env is optional; in containers the variables come from the environment
+	if _, err := os.Stat(".env"); err == nil {
+		dotenv := viper.New()
+		dotenv.SetConfigFile(".env")
+		dotenv.SetConfigType("env")
+		if err := dotenv.ReadInConfig(); err != nil {
+			return nil, apperrors.Wrap(err, "CONFIG_FILE_ERROR", "failed to read .env")
+		}
+		values := make(map[string]interface{})
+		for _, key := range dotenv.AllKeys() {
+			values[key] = dotenv.Get(key)
+			sources[strings.ToUpper(key)] = ".env"
+		}
+		if err := v.MergeConfigMap(values); err != nil {
+			return nil, apperrors.Wrap(err, "CONFIG_FILE_ERROR", "failed to merge .env")
+		}
+	}
+
+	v.AutomaticEnv()
+	for _, s := range settings {
+		if os.Getenv(s.key) != "" {
+			sources[s.key] = "env"
+		}
+	}
+
+	var problems []string
+	for _, s := range settings {
+		path := v.GetString(s.key + "_FILE")
+		if path == "" {
+			continue
+		}
+		secret, err := os.ReadFile(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s_FILE: %v", s.key, err))
+			continue
+		}
+		v.Set(s.key, strings.TrimSpace(string(secret)))
+		sources[s.key] = path
+	}
+
+	if l.flags != nil {
+		byFlag := make(map[string]setting, len(settings))
+		for _, s := range settings {
+			byFlag[s.flag()] = s
+		}
+		l.flags.Visit(func(f *flag.Flag) {
+			if s, ok := byFlag[f.Name]; ok {
+				v.Set(s.key, f.Value.String())
+				sources[s.key] = "flag"
+			}
+		})
+	}
+
+	for _, key := range []string{"ERROR_REPORT_INTERVAL", "ERROR_REPORT_COOLDOWN", "SHUTDOWN_TIMEOUT"} {
+		if d, err := time.ParseDuration(v.GetString(key)); err != nil || d <= 0 {
+			problems = append(problems, fmt.Sprintf("%s: %q is not a positive duration", key, v.GetString(key)))
+		}
+	}
+
+	for _, key := range []string{"LOG_MAX_SIZE_MB", "LOG_MAX_BACKUPS", "ERROR_REPORT_LIMIT"} {
+		if n, err := strconv.Atoi(v.GetString(key)); err != nil || n < 0 {
+			problems = append(problems, fmt.Sprintf("%s: %q is not a non-negative number", key, v.GetString(key)))
+		}
+	}
+
+	if rate, err := strconv.ParseFloat(v.GetString("TRACE_SAMPLE_RATE"), 64); err != nil || rate < 0 || rate > 1 {
+		problems = append(problems, fmt.Sprintf("TRACE_SAMPLE_RATE: %q is not a number from 0 to 1", v.GetString("TRACE_SAMPLE_RATE")))
+	}
+
+	if _, err := strconv.ParseBool(v.GetString("SCHEMA_STRICT")); err != nil {
+		problems = append(problems, fmt.Sprintf("SCHEMA_STRICT: %q is not a boolean", v.GetString("SCHEMA_STRICT")))
 	}
 
 	cfg := &Config{
@@ -61,11 +327,216 @@ func NewConfig(opts ...Option) (*Config, error) {
 			Password: v.GetString("REDIS_PASSWORD"),
 		},
 		HdevApiKey: v.GetString("HENRIKDEV_API_KEY"),
+		APIs: APIConfig{
+			HenrikBaseURL:   strings.TrimSuffix(v.GetString("HENRIKDEV_BASE_URL"), "/"),
+			TrackerBaseURL:  strings.TrimSuffix(v.GetString("TRACKER_BASE_URL"), "/"),
+			ValorantBaseURL: strings.TrimSuffix(v.GetString("VALORANT_API_BASE_URL"), "/"),
+		},
+		Fixtures: FixturesConfig{
+			Mode: strings.ToLower(v.GetString("FIXTURES_MODE")),
+			Dir:  v.GetString("FIXTURES_DIR"),
+		},
+		Log: LogConfig{
+			Format:     strings.ToLower(v.GetString("LOG_FORMAT")),
+			File:       v.GetString("LOG_FILE"),
+			MaxSizeMB:  v.GetInt("LOG_MAX_SIZE_MB"),
+			MaxBackups: v.GetInt("LOG_MAX_BACKUPS"),
+		},
+		ErrorReports: ErrorReportConfig{
+			Webhook:     v.GetString("ERROR_REPORT_WEBHOOK"),
+			ChannelID:   v.GetString("ERROR_REPORT_CHANNEL"),
+			Interval:    v.GetDuration("ERROR_REPORT_INTERVAL"),
+			Cooldown:    v.GetDuration("ERROR_REPORT_COOLDOWN"),
+			HourlyLimit: v.GetInt("ERROR_REPORT_LIMIT"),
+			Ignore:      splitList(v.GetString("ERROR_REPORT_IGNORE"), strings.ToUpper),
+		},
+		Owners:      splitList(v.GetString("BOT_OWNERS"), nil),
+		MetricsAddr: v.GetString("METRICS_ADDR"),
+		Tracing: TracingConfig{
+			Exporter:    strings.ToLower(v.GetString("TRACE_EXPORTER")),
+			Endpoint:    strings.TrimSuffix(v.GetString("TRACE_OTLP_ENDPOINT"), "/"),
+			ServiceName: v.GetString("TRACE_SERVICE_NAME"),
+			SampleRate:  v.GetFloat64("TRACE_SAMPLE_RATE"),
+		},
+		ShutdownTimeout: v.GetDuration("SHUTDOWN_TIMEOUT"),
+		SchemaStrict:    v.GetBool("SCHEMA_STRICT"),
+		ComponentSecret: v.GetString("COMPONENT_SIGNING_SECRET"),
+		values:          make(map[string]string, len(settings)),
+		sources:         sources,
+		problems:        problems,
+	}
+	for _, s := range settings {
+		cfg.values[s.key] = v.GetString(s.key)
 	}
 
+	runtime, runtimeProblems := parseRuntime(v.GetString)
+	cfg.runtime.Store(runtime)
+	cfg.problems = append(cfg.problems, runtimeProblems...)
+
+	cfg.CooldownBypassRoles = splitList(v.GetString("COOLDOWN_BYPASS_ROLES"), nil)
+	cfg.DisabledFeatures = splitList(v.GetString("DISABLED_FEATURES"), strings.ToLower)
+
 	for _, opt := range opts {
 		opt(cfg)
 	}
 
 	return cfg, nil
 }
+
+// splitList splits a comma-separated setting, dropping empty entries and
+// applying normalize to the rest.
+func splitList(raw string, normalize func(string) string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		if normalize != nil {
+			item = normalize(item)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func (l *Loader) configFile() string {
+	if l.file != "" {
+		return l.file
+	}
+	return os.Getenv("CONFIG_FILE")
+}
+
+// files lists the files Load reads, for watching.
+func (l *Loader) files() []string {
+	var files []string
+	if file := l.configFile(); file != "" {
+		files = append(files, file)
+	}
+	if _, err := os.Stat(".env"); err == nil {
+		files = append(files, ".env")
+	}
+	return files
+}
+
+// Validate checks everything the bot needs to start and reports every problem
+// in a single error.
+func (c *Config) Validate() error {
+	problems := append([]string(nil), c.problems...)
+
+	required := map[string]string{
+		"DISCORD_BOT_TOKEN": c.DiscordBotToken,
+		"HENRIKDEV_API_KEY": c.HdevApiKey,
+		"DB_HOST":           c.DB.Host,
+		"DB_USER":           c.DB.User,
+		"DB_NAME":           c.DB.Name,
+		"REDIS_HOST":        c.Redis.Host,
+	}
+	for _, s := range settings {
+		if value, ok := required[s.key]; ok && strings.TrimSpace(value) == "" {
+			problems = append(problems, s.key+" is required")
+		}
+	}
+
+	for key, port := range map[string]string{"DB_PORT": c.DB.Port, "REDIS_PORT": c.Redis.Port} {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			problems = append(problems, fmt.Sprintf("%s: %q is not a valid port", key, port))
+		}
+	}
+
+	for key, raw := range map[string]string{
+		"HENRIKDEV_BASE_URL":    c.APIs.HenrikBaseURL,
+		"TRACKER_BASE_URL":      c.APIs.TrackerBaseURL,
+		"VALORANT_API_BASE_URL": c.APIs.ValorantBaseURL,
+	} {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("%s: %q is not an http(s) URL", key, raw))
+		}
+	}
+
+	switch c.Fixtures.Mode {
+	case "", "record", "replay":
+	default:
+		problems = append(problems, fmt.Sprintf("FIXTURES_MODE: %q, expected record or replay", c.Fixtures.Mode))
+	}
+
+	if c.ErrorReports.Webhook != "" {
+		if _, _, ok := WebhookCredentials(c.ErrorReports.Webhook); !ok {
+			problems = append(problems, "ERROR_REPORT_WEBHOOK: expected a Discord webhook URL")
+		}
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("TRACE_OTLP_ENDPOINT: %q is not an http(s) URL", c.Tracing.Endpoint))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("TRACE_EXPORTER: %q, expected none, stdout or otlp", c.Tracing.Exporter))
+	}
+
+	switch c.Log.Format {
+	case "text", "json", "logfmt":
+	default:
+		problems = append(problems, fmt.Sprintf("LOG_FORMAT: %q, expected text, json or logfmt", c.Log.Format))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return apperrors.New("CONFIG_INVALID", fmt.Sprintf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - ")))
+}
+
+// Print writes every setting with where its value came from. Secrets only
+// show whether they are set.
+func (c *Config) Print(w io.Writer) {
+	for _, s := range settings {
+		value := c.values[s.key]
+		if s.secret && value != "" {
+			value = "<redacted>"
+		}
+		source := c.sources[s.key]
+		if s.reloadable {
+			source += ", reloadable"
+		}
+		fmt.Fprintf(w, "%-26s %-62s (%s)\n", s.key, strconv.Quote(value), source)
+	}
+}
+
+// IsOwner reports whether userID is listed in BOT_OWNERS.
+func (c *Config) IsOwner(userID string) bool {
+	for _, owner := range c.Owners {
+		if owner == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookCredentials extracts the ID and token from a Discord webhook URL such
+// as https://discord.com/api/webhooks/<id>/<token>.
+func WebhookCredentials(raw string) (string, string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", "", false
+	}
+	_, rest, ok := strings.Cut(u.Path, "/webhooks/")
+	if !ok {
+		return "", "", false
+	}
+	id, token, ok := strings.Cut(strings.Trim(rest, "/"), "/")
+	if !ok || id == "" || token == "" || strings.Contains(token, "/") {
+		return "", "", false
+	}
+	return id, token, true
+}
+
+func (c *Config) FeatureEnabled(name string) bool {
+	for _, disabled := range c.DisabledFeatures {
+		if disabled == name {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,13 @@
+package config
+
+import "yk-dc-bot/internal/logger"
+
+// NewLogger builds the process logger from the LOG_* settings. The level is
+// left to the reloader since LOG_LEVEL can change at runtime.
+func NewLogger(cfg *Config) *logger.Logger {
+	opts := []logger.Option{logger.WithFormat(cfg.Log.Format)}
+	if cfg.Log.File != "" {
+		opts = append(opts, logger.WithFile(cfg.Log.File, cfg.Log.MaxSizeMB, cfg.Log.MaxBackups))
+	}
+	return logger.NewLogger(opts...)
+}
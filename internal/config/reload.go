@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"yk-dc-bot/internal/logger"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Reloader re-reads the configuration on SIGHUP or when a config file changes
+// and swaps in the new Runtime settings. Everything else needs a restart.
+type Reloader struct {
+	loader *Loader
+	cfg    *Config
+	log    *logger.Logger
+
+	mu          sync.Mutex
+	subscribers []func(*Runtime)
+	signals     chan os.Signal
+	watcher     *fsnotify.Watcher
+	done        sync.WaitGroup
+}
+
+func NewReloader(loader *Loader, cfg *Config, log *logger.Logger) *Reloader {
+	return &Reloader{loader: loader, cfg: cfg, log: log}
+}
+
+// Subscribe calls fn with the current settings and again after every
+// successful reload.
+func (r *Reloader) Subscribe(fn func(*Runtime)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+	fn(r.cfg.Runtime())
+}
+
+// Reload loads and validates the configuration again. An invalid config is
+// rejected and the current settings stay in place.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := r.loader.Load()
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		r.log.Error("Rejected config reload, keeping the current settings", "error", err)
+		return err
+	}
+
+	var changed, restart []string
+	for _, s := range settings {
+		before, after := r.cfg.values[s.key], next.values[s.key]
+		if before == after {
+			continue
+		}
+		switch {
+		case !s.reloadable:
+			restart = append(restart, s.key)
+		case s.secret:
+			changed = append(changed, s.key)
+		default:
+			changed = append(changed, fmt.Sprintf("%s=%s (was %s)", s.key, after, before))
+		}
+	}
+
+	if len(restart) > 0 {
+		r.log.Warn("Some changed settings only apply after a restart", "settings", strings.Join(restart, ", "))
+	}
+	if len(changed) == 0 {
+		r.log.Info("Config reloaded, no runtime settings changed")
+		return nil
+	}
+
+	runtime := next.Runtime()
+	r.cfg.runtime.Store(runtime)
+	for _, s := range settings {
+		if s.reloadable {
+			r.cfg.values[s.key] = next.values[s.key]
+			r.cfg.sources[s.key] = next.sources[s.key]
+		}
+	}
+	for _, fn := range r.subscribers {
+		fn(runtime)
+	}
+
+	r.log.Info("Config reloaded", "changed", strings.Join(changed, "; "))
+	return nil
+}
+
+// Start reloads on SIGHUP and whenever the config file or .env is written.
+func (r *Reloader) Start() {
+	r.signals = make(chan os.Signal, 1)
+	signal.Notify(r.signals, syscall.SIGHUP)
+	r.done.Add(1)
+	go func() {
+		defer r.done.Done()
+		for range r.signals {
+			r.log.Info("Received SIGHUP, reloading config")
+			_ = r.Reload()
+		}
+	}()
+
+	files := r.loader.files()
+	if len(files) == 0 {
+		return
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		r.log.Warn("Failed to watch config files, only SIGHUP reloads", "error", err)
+		return
+	}
+	r.watcher = watcher
+
+	// editors often replace a file rather than write to it, so watch the
+	// directories and pick out the files' events
+	watched := make(map[string]bool, len(files))
+	for _, file := range files {
+		path, err := filepath.Abs(file)
+		if err != nil {
+			path = file
+		}
+		watched[path] = true
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			r.log.Warn("Failed to watch config file", "file", file, "error", err)
+		}
+	}
+
+	r.done.Add(1)
+	go func() {
+		defer r.done.Done()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if watched[filepath.Clean(event.Name)] && event.Has(fsnotify.Write|fsnotify.Create) {
+					r.log.Info("Config file changed, reloading", "file", event.Name)
+					_ = r.Reload()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				r.log.Warn("Config file watcher failed", "error", err)
+			}
+		}
+	}()
+}
+
+// Stop stops watching for SIGHUP and file changes and waits for a reload in
+// progress to finish.
+func (r *Reloader) Stop() {
+	if r.signals != nil {
+		signal.Stop(r.signals)
+		close(r.signals)
+	}
+	if r.watcher != nil {
+		r.watcher.Close()
+	}
+	r.done.Wait()
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Runtime is the part of the configuration that can change while the bot is
+// running. Read it through Config.Runtime at the point of use instead of
+// copying values out at startup.
+type Runtime struct {
+	LogLevel  string
+	CacheTTLs CacheTTLs
+	// Cooldowns replace the limits a command declares, keyed by command name.
+	Cooldowns      map[string][]CooldownLimit
+	TrackerProxies []string
+}
+
+type CacheTTLs struct {
+	Account         time.Duration
+	DetailedAccount time.Duration
+	MMR             time.Duration
+	Matches         time.Duration
+	Match           time.Duration
+	Tracker         time.Duration
+}
+
+type CooldownLimit struct {
+	Scope  string
+	Burst  int
+	Period time.Duration
+}
+
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// defaultRuntime backs configs that weren't loaded, such as the zero Config
+// the command export and tests use.
+var defaultRuntime = func() *Runtime {
+	runtime, _ := parseRuntime(func(key string) string {
+		for _, s := range settings {
+			if s.key == key {
+				return s.def
+			}
+		}
+		return ""
+	})
+	return runtime
+}()
+
+// Runtime returns the current reloadable settings.
+func (c *Config) Runtime() *Runtime {
+	if runtime := c.runtime.Load(); runtime != nil {
+		return runtime
+	}
+	return defaultRuntime
+}
+
+func parseRuntime(get func(key string) string) (*Runtime, []string) {
+	var problems []string
+	runtime := &Runtime{
+		LogLevel:  strings.ToLower(get("LOG_LEVEL")),
+		Cooldowns: make(map[string][]CooldownLimit),
+	}
+
+	validLevel := false
+	for _, level := range logLevels {
+		validLevel = validLevel || runtime.LogLevel == level
+	}
+	if !validLevel {
+		problems = append(problems, fmt.Sprintf("LOG_LEVEL: %q, expected one of %s", runtime.LogLevel, strings.Join(logLevels, ", ")))
+	}
+
+	for key, ttl := range map[string]*time.Duration{
+		"CACHE_TTL_ACCOUNT":          &runtime.CacheTTLs.Account,
+		"CACHE_TTL_DETAILED_ACCOUNT": &runtime.CacheTTLs.DetailedAccount,
+		"CACHE_TTL_MMR":              &runtime.CacheTTLs.MMR,
+		"CACHE_TTL_MATCHES":          &runtime.CacheTTLs.Matches,
+		"CACHE_TTL_MATCH":            &runtime.CacheTTLs.Match,
+		"CACHE_TTL_TRACKER":          &runtime.CacheTTLs.Tracker,
+	} {
+		d, err := time.ParseDuration(get(key))
+		if err != nil || d <= 0 {
+			problems = append(problems, fmt.Sprintf("%s: %q is not a positive duration", key, get(key)))
+			continue
+		}
+		*ttl = d
+	}
+
+	// COOLDOWNS looks like "tracker=user:2/1m,guild:10/1m;heatmap=user:3/30s"
+	for _, entry := range strings.Split(get("COOLDOWNS"), ";") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		command, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			problems = append(problems, fmt.Sprintf("COOLDOWNS: %q should be command=scope:burst/period", entry))
+			continue
+		}
+		command = strings.TrimSpace(strings.ToLower(command))
+		limits := []CooldownLimit{}
+		for _, raw := range strings.Split(spec, ",") {
+			limit, err := parseCooldown(strings.TrimSpace(raw))
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("COOLDOWNS: %s: %v", command, err))
+				continue
+			}
+			limits = append(limits, limit)
+		}
+		runtime.Cooldowns[command] = limits
+	}
+
+	for _, proxy := range strings.Split(get("TRACKER_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			runtime.TrackerProxies = append(runtime.TrackerProxies, proxy)
+		}
+	}
+
+	return runtime, problems
+}
+
+func parseCooldown(raw string) (CooldownLimit, error) {
+	scope, rate, ok := strings.Cut(raw, ":")
+	if !ok {
+		return CooldownLimit{}, fmt.Errorf("%q should be scope:burst/period", raw)
+	}
+	switch scope {
+	case "user", "guild", "channel":
+	default:
+		return CooldownLimit{}, fmt.Errorf("unknown scope %q, expected user, guild or channel", scope)
+	}
+
+	burst, period, ok := strings.Cut(rate, "/")
+	if !ok {
+		return CooldownLimit{}, fmt.Errorf("%q should be scope:burst/period", raw)
+	}
+	n, err := strconv.Atoi(burst)
+	if err != nil || n < 1 {
+		return CooldownLimit{}, fmt.Errorf("burst %q is not a positive number", burst)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return CooldownLimit{}, fmt.Errorf("period %q is not a positive duration", period)
+	}
+	return CooldownLimit{Scope: scope, Burst: n, Period: d}, nil
+}
@@ -6,12 +6,27 @@ import (
 	"strings"
 	"time"
 	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/models"
 
 	"github.com/jmoiron/sqlx"
 )
 
 var tables = []interface{}{
 	// models.User{},
+	models.Match{},
+	models.MatchPlayer{},
+	models.GuildSetting{},
+	models.ErrorOccurrence{},
+}
+
+// tableNamer lets a model override the default "<lowercase type name>s" table name.
+type tableNamer interface {
+	TableName() string
+}
+
+// tableConstrainer lets a model add table-level constraints such as composite keys.
+type tableConstrainer interface {
+	TableConstraints() []string
 }
 
 func RunMigrations(db *sqlx.DB) error {
@@ -26,6 +41,9 @@ func RunMigrations(db *sqlx.DB) error {
 func createTableIfNotExists(db *sqlx.DB, model interface{}) error {
 	tableName := getTableName(model)
 	columns := getColumns(model)
+	if c, ok := model.(tableConstrainer); ok {
+		columns = append(columns, c.TableConstraints()...)
+	}
 
 	query := fmt.Sprintf(`
 		CREATE TABLE IF NOT EXISTS %s (
@@ -42,6 +60,9 @@ func createTableIfNotExists(db *sqlx.DB, model interface{}) error {
 }
 
 func getTableName(model interface{}) string {
+	if n, ok := model.(tableNamer); ok {
+		return n.TableName()
+	}
 	t := reflect.TypeOf(model)
 	return strings.ToLower(t.Name()) + "s"
 }
@@ -0,0 +1,96 @@
+package errorreport
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"yk-dc-bot/internal/apperrors"
+)
+
+// maxChainLinks bounds how much of a deeply wrapped error is kept.
+const maxChainLinks = 8
+
+// Occurrence is a single failure along with where it happened.
+type Occurrence struct {
+	Code        string
+	Fingerprint string
+	// Message is the log message apperrors.HandleError produced.
+	Message       string
+	Chain         []string
+	Command       string
+	GuildID       string
+	UserID        string
+	CorrelationID string
+	OccurredAt    time.Time
+}
+
+// Source is the interaction an error came from.
+type Source struct {
+	Command       string
+	GuildID       string
+	UserID        string
+	CorrelationID string
+}
+
+// NewOccurrence describes err as it failed action. Errors that aren't
+// AppErrors are reported under the UNEXPECTED code.
+func NewOccurrence(err error, action string, src Source) Occurrence {
+	code := "UNEXPECTED"
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		code = appErr.Code
+	}
+	_, message := apperrors.HandleError(err, action)
+	chain := Chain(err)
+
+	return Occurrence{
+		Code:          code,
+		Fingerprint:   Fingerprint(code, src.Command, chain),
+		Message:       message,
+		Chain:         chain,
+		Command:       src.Command,
+		GuildID:       src.GuildID,
+		UserID:        src.UserID,
+		CorrelationID: src.CorrelationID,
+		OccurredAt:    time.Now().UTC(),
+	}
+}
+
+// Chain lists err and everything it wraps, outermost first. AppErrors show
+// their own code and message rather than the whole wrapped text.
+func Chain(err error) []string {
+	var chain []string
+	for err != nil && len(chain) < maxChainLinks {
+		if appErr, ok := err.(*apperrors.AppError); ok {
+			chain = append(chain, appErr.Code+": "+appErr.Message)
+		} else {
+			chain = append(chain, err.Error())
+		}
+		err = errors.Unwrap(err)
+	}
+	return chain
+}
+
+// volatile matches the parts of an error message that change between
+// otherwise identical failures: IDs, hashes, ports, durations and counts.
+var volatile = regexp.MustCompile(`[0-9a-fA-F]{8}(-?[0-9a-fA-F]{4}){3}-?[0-9a-fA-F]{12}|[0-9a-fA-F]{16,}|\d+(\.\d+)?`)
+
+// Fingerprint groups occurrences of the same failure. It covers the code, the
+// command and the innermost cause with volatile values masked, so a timeout
+// and a refused connection under the same code are told apart while two
+// players' timeouts are not.
+func Fingerprint(code, command string, chain []string) string {
+	root := ""
+	if len(chain) > 0 {
+		root = volatile.ReplaceAllString(chain[len(chain)-1], "#")
+	}
+	// component custom IDs carry their arguments after the route name
+	command, _, _ = strings.Cut(command, ":")
+
+	sum := sha1.Sum([]byte(code + "\x00" + command + "\x00" + root))
+	return hex.EncodeToString(sum[:])[:10]
+}
@@ -0,0 +1,53 @@
+package errorreport
+
+import (
+	"context"
+	"time"
+
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/models"
+)
+
+// Summary is one group of stored occurrences, described by its latest one.
+type Summary struct {
+	Code          string    `db:"code"`
+	Fingerprint   string    `db:"fingerprint"`
+	Count         int       `db:"count"`
+	LastSeen      time.Time `db:"last_seen"`
+	Message       string    `db:"message"`
+	Command       string    `db:"command"`
+	CorrelationID string    `db:"correlation_id"`
+}
+
+// Recent groups the occurrences of the last window, most recently seen first.
+func (r *Reporter) Recent(ctx context.Context, window time.Duration, limit int) ([]Summary, error) {
+	var summaries []Summary
+	err := r.db.SelectContext(ctx, &summaries, `
+		SELECT code, fingerprint, COUNT(*) AS count, MAX(occurred_at) AS last_seen,
+			(ARRAY_AGG(message ORDER BY occurred_at DESC))[1] AS message,
+			(ARRAY_AGG(command ORDER BY occurred_at DESC))[1] AS command,
+			(ARRAY_AGG(correlation_id ORDER BY occurred_at DESC))[1] AS correlation_id
+		FROM error_occurrences
+		WHERE occurred_at > $1
+		GROUP BY code, fingerprint
+		ORDER BY last_seen DESC
+		LIMIT $2
+	`, time.Now().UTC().Add(-window), limit)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "ERROR_REPORT_QUERY_ERROR", "failed to load recent errors")
+	}
+	return summaries, nil
+}
+
+// ByReference returns the occurrences logged under a correlation ID, the
+// "ref" users see on error embeds.
+func (r *Reporter) ByReference(ctx context.Context, correlationID string) ([]models.ErrorOccurrence, error) {
+	var occurrences []models.ErrorOccurrence
+	err := r.db.SelectContext(ctx, &occurrences, `
+		SELECT * FROM error_occurrences WHERE correlation_id = $1 ORDER BY occurred_at
+	`, correlationID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "ERROR_REPORT_QUERY_ERROR", "failed to load errors for reference "+correlationID)
+	}
+	return occurrences, nil
+}
@@ -0,0 +1,306 @@
+package errorreport
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/database"
+	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/models"
+	"yk-dc-bot/internal/util"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// queueSize bounds how many occurrences can wait for the worker; past it
+	// new ones are dropped rather than blocking the handler.
+	queueSize = 256
+	// maxGroupsPerReport keeps a report within Discord's embed size limits.
+	maxGroupsPerReport = 5
+	maxChainLength     = 700
+	// retention is how long occurrences are kept for /errors.
+	retention = 30 * 24 * time.Hour
+)
+
+type group struct {
+	count      int
+	firstSeen  time.Time
+	latest     Occurrence
+	users      map[string]struct{}
+	guilds     map[string]struct{}
+	lastPosted time.Time
+}
+
+// Reporter stores every reported failure in Postgres and posts them, grouped
+// by code and fingerprint, to an ops webhook or channel. The same failure is
+// posted at most once per cooldown; occurrences in between are counted and
+// show up in the next report.
+type Reporter struct {
+	cfg    config.ErrorReportConfig
+	db     *database.Database
+	log    *logger.Logger
+	ignore map[string]struct{}
+
+	occurrences chan Occurrence
+	session     *discordgo.Session
+
+	// groups and posted are only touched by the worker goroutine.
+	groups map[string]*group
+	posted []time.Time
+
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+func NewReporter(cfg *config.Config, db *database.Database, log *logger.Logger) *Reporter {
+	ignore := make(map[string]struct{}, len(cfg.ErrorReports.Ignore))
+	for _, code := range cfg.ErrorReports.Ignore {
+		ignore[code] = struct{}{}
+	}
+	return &Reporter{
+		cfg:         cfg.ErrorReports,
+		db:          db,
+		log:         log,
+		ignore:      ignore,
+		occurrences: make(chan Occurrence, queueSize),
+		groups:      make(map[string]*group),
+	}
+}
+
+// Report queues an occurrence without blocking. It is a no-op on a nil
+// Reporter and for ignored codes.
+func (r *Reporter) Report(o Occurrence) {
+	if r == nil {
+		return
+	}
+	if _, ignored := r.ignore[o.Code]; ignored {
+		return
+	}
+	select {
+	case r.occurrences <- o:
+	default:
+		r.log.Warn("Error report queue is full, dropping occurrence", "code", o.Code, "correlation_id", o.CorrelationID)
+	}
+}
+
+// Start begins storing and posting reports through session.
+func (r *Reporter) Start(session *discordgo.Session) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.session = session
+
+	if r.cfg.Webhook == "" && r.cfg.ChannelID == "" {
+		r.log.Info("No error report webhook or channel configured, errors are only stored")
+	}
+
+	r.done.Add(1)
+	go r.run(ctx)
+}
+
+// Stop stores what is still queued, posts a final report and waits for the
+// worker to finish.
+func (r *Reporter) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	r.done.Wait()
+}
+
+func (r *Reporter) run(ctx context.Context) {
+	defer r.done.Done()
+
+	flush := time.NewTicker(r.cfg.Interval)
+	defer flush.Stop()
+	prune := time.NewTicker(24 * time.Hour)
+	defer prune.Stop()
+	r.prune()
+
+	for {
+		select {
+		case o := <-r.occurrences:
+			r.record(o)
+		case now := <-flush.C:
+			r.flush(now)
+		case <-prune.C:
+			r.prune()
+		case <-ctx.Done():
+			for {
+				select {
+				case o := <-r.occurrences:
+					r.record(o)
+				default:
+					r.flush(time.Now())
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *Reporter) record(o Occurrence) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := r.db.NamedExecContext(ctx, `
+		INSERT INTO error_occurrences (code, fingerprint, message, chain, command, guild_id, user_id, correlation_id, occurred_at)
+		VALUES (:code, :fingerprint, :message, :chain, :command, :guild_id, :user_id, :correlation_id, :occurred_at)
+	`, models.ErrorOccurrence{
+		Code:          o.Code,
+		Fingerprint:   o.Fingerprint,
+		Message:       o.Message,
+		Chain:         strings.Join(o.Chain, "\n"),
+		Command:       o.Command,
+		GuildID:       o.GuildID,
+		UserID:        o.UserID,
+		CorrelationID: o.CorrelationID,
+		OccurredAt:    o.OccurredAt,
+	})
+	if err != nil {
+		r.log.Warn("Failed to store error occurrence", "code", o.Code, "error", err)
+	}
+
+	key := o.Code + "/" + o.Fingerprint
+	g, ok := r.groups[key]
+	if !ok {
+		g = &group{}
+		r.groups[key] = g
+	}
+	if g.count == 0 {
+		g.firstSeen = o.OccurredAt
+		g.users = make(map[string]struct{})
+		g.guilds = make(map[string]struct{})
+	}
+	g.count++
+	g.latest = o
+	if o.UserID != "" {
+		g.users[o.UserID] = struct{}{}
+	}
+	if o.GuildID != "" {
+		g.guilds[o.GuildID] = struct{}{}
+	}
+}
+
+// flush posts every group that has new occurrences and is out of its
+// cooldown, as long as the hourly limit allows another report.
+func (r *Reporter) flush(now time.Time) {
+	var due []*group
+	for key, g := range r.groups {
+		if g.count == 0 {
+			if now.Sub(g.lastPosted) >= r.cfg.Cooldown {
+				delete(r.groups, key)
+			}
+			continue
+		}
+		if g.lastPosted.IsZero() || now.Sub(g.lastPosted) >= r.cfg.Cooldown {
+			due = append(due, g)
+		}
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	if r.cfg.Webhook == "" && r.cfg.ChannelID == "" {
+		for _, g := range due {
+			g.count = 0
+			g.lastPosted = now
+		}
+		return
+	}
+
+	recent := r.posted[:0]
+	for _, t := range r.posted {
+		if now.Sub(t) < time.Hour {
+			recent = append(recent, t)
+		}
+	}
+	r.posted = recent
+	if len(r.posted) >= r.cfg.HourlyLimit {
+		return
+	}
+
+	sort.Slice(due, func(a, b int) bool { return due[a].count > due[b].count })
+	if len(due) > maxGroupsPerReport {
+		due = due[:maxGroupsPerReport]
+	}
+
+	embeds := make([]*discordgo.MessageEmbed, 0, len(due))
+	for _, g := range due {
+		embeds = append(embeds, groupEmbed(g))
+	}
+	if err := r.send(embeds); err != nil {
+		r.log.Warn("Failed to post error report", "error", err)
+		return
+	}
+
+	r.posted = append(r.posted, now)
+	for _, g := range due {
+		g.count = 0
+		g.lastPosted = now
+	}
+}
+
+func (r *Reporter) send(embeds []*discordgo.MessageEmbed) error {
+	if r.session == nil {
+		return apperrors.New("ERROR_REPORT_ERROR", "reporter was not started")
+	}
+	if r.cfg.Webhook != "" {
+		id, token, _ := config.WebhookCredentials(r.cfg.Webhook)
+		_, err := r.session.WebhookExecute(id, token, false, &discordgo.WebhookParams{Embeds: embeds})
+		return err
+	}
+	_, err := r.session.ChannelMessageSendEmbeds(r.cfg.ChannelID, embeds)
+	return err
+}
+
+func (r *Reporter) prune() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM error_occurrences WHERE occurred_at < $1`, time.Now().UTC().Add(-retention)); err != nil {
+		r.log.Warn("Failed to prune old error occurrences", "error", err)
+	}
+}
+
+func groupEmbed(g *group) *discordgo.MessageEmbed {
+	o := g.latest
+	chain := strings.Join(o.Chain, "\n↳ ")
+	if runes := []rune(chain); len(runes) > maxChainLength {
+		chain = string(runes[:maxChainLength]) + "…"
+	}
+
+	title := o.Code
+	if g.count > 1 {
+		title = fmt.Sprintf("%s ×%d", o.Code, g.count)
+	}
+
+	builder := util.NewEmbed(util.StyleError, title, fmt.Sprintf("%s\n```\n%s\n```", o.Message, chain)).
+		WithField("command", "/"+orNone(o.Command), true).
+		WithField("guild", orNone(o.GuildID), true).
+		WithField("user", mention(o.UserID), true).
+		WithField("reference", "`"+orNone(o.CorrelationID)+"`", true).
+		WithField("fingerprint", "`"+o.Fingerprint+"`", true)
+	if g.count > 1 {
+		builder = builder.WithField("spread", fmt.Sprintf("%d users in %d guilds since <t:%d:R>", len(g.users), len(g.guilds), g.firstSeen.Unix()), true)
+	}
+	return builder.Build()
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+func mention(userID string) string {
+	if userID == "" {
+		return "none"
+	}
+	return "<@" + userID + ">"
+}
@@ -0,0 +1,248 @@
+package fixtures
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/logger"
+)
+
+const (
+	ModeOff    = ""
+	ModeRecord = "record"
+	ModeReplay = "replay"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveHeaders and sensitiveParams are replaced before a fixture is
+// written, so recordings can be committed.
+var (
+	sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+	sensitiveParams  = []string{"api_key", "apikey", "key", "token"}
+)
+
+// Fixture is a recorded HTTP exchange. JSON bodies are stored as-is to keep
+// recordings readable and editable; anything else is base64 encoded.
+type Fixture struct {
+	Method         string          `json:"method"`
+	URL            string          `json:"url"`
+	RequestHeaders http.Header     `json:"request_headers,omitempty"`
+	Status         int             `json:"status"`
+	Headers        http.Header     `json:"headers,omitempty"`
+	Body           json.RawMessage `json:"body,omitempty"`
+	BodyBase64     string          `json:"body_base64,omitempty"`
+}
+
+func (f *Fixture) SetBody(body []byte) {
+	if json.Valid(body) {
+		f.Body = append(json.RawMessage(nil), body...)
+		f.BodyBase64 = ""
+		return
+	}
+	f.Body = nil
+	f.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+}
+
+func (f *Fixture) Bytes() []byte {
+	if f.Body != nil {
+		return f.Body
+	}
+	body, _ := base64.StdEncoding.DecodeString(f.BodyBase64)
+	return body
+}
+
+type Store struct {
+	mode string
+	dir  string
+	log  *logger.Logger
+}
+
+func NewStore(cfg *config.Config, log *logger.Logger) (*Store, error) {
+	switch cfg.Fixtures.Mode {
+	case ModeOff:
+	case ModeRecord, ModeReplay:
+		log.Warn("HTTP fixtures enabled", "mode", cfg.Fixtures.Mode, "dir", cfg.Fixtures.Dir)
+	default:
+		return nil, apperrors.New("FIXTURES_CONFIG_ERROR", fmt.Sprintf("unknown FIXTURES_MODE %q, expected record or replay", cfg.Fixtures.Mode))
+	}
+
+	return &Store{mode: cfg.Fixtures.Mode, dir: cfg.Fixtures.Dir, log: log}, nil
+}
+
+func (s *Store) Mode() string {
+	if s == nil {
+		return ModeOff
+	}
+	return s.mode
+}
+
+// Load returns the fixture recorded for a request.
+func (s *Store) Load(method, rawURL string) (*Fixture, error) {
+	path := s.path(method, rawURL)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "FIXTURE_MISSING", fmt.Sprintf("no fixture for %s %s at %s, record it first", method, redactURL(rawURL), path))
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, apperrors.Wrap(err, "FIXTURE_PARSE_ERROR", fmt.Sprintf("failed to parse fixture %s", path))
+	}
+	return &fixture, nil
+}
+
+// Save redacts and writes a fixture, replacing any earlier recording of the
+// same request.
+func (s *Store) Save(fixture *Fixture) error {
+	path := s.path(fixture.Method, fixture.URL)
+
+	redactedFixture := *fixture
+	redactedFixture.URL = redactURL(fixture.URL)
+	redactedFixture.RequestHeaders = redactHeaders(fixture.RequestHeaders)
+	redactedFixture.Headers = redactHeaders(fixture.Headers)
+	// bodies are re-indented on disk, so the recorded length would be wrong
+	redactedFixture.Headers.Del("Content-Length")
+
+	data, err := json.MarshalIndent(redactedFixture, "", "  ")
+	if err != nil {
+		return apperrors.Wrap(err, "FIXTURE_WRITE_ERROR", "failed to encode fixture")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return apperrors.Wrap(err, "FIXTURE_WRITE_ERROR", fmt.Sprintf("failed to create %s", filepath.Dir(path)))
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return apperrors.Wrap(err, "FIXTURE_WRITE_ERROR", fmt.Sprintf("failed to write %s", path))
+	}
+
+	s.log.Debug("Recorded fixture", "path", path)
+	return nil
+}
+
+// Transport wraps next so requests are recorded or replayed according to the
+// store's mode. With fixtures off it returns next unchanged.
+func (s *Store) Transport(next http.RoundTripper) http.RoundTripper {
+	if s.Mode() == ModeOff {
+		return next
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{store: s, next: next}
+}
+
+type transport struct {
+	store *Store
+	next  http.RoundTripper
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if t.store.mode == ModeReplay {
+		fixture, err := t.store.Load(r.Method, r.URL.String())
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", fixture.Status, http.StatusText(fixture.Status)),
+			StatusCode:    fixture.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        fixture.Headers.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(fixture.Bytes())),
+			ContentLength: int64(len(fixture.Bytes())),
+			Request:       r,
+		}, nil
+	}
+
+	resp, err := t.next.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	fixture := &Fixture{
+		Method:         r.Method,
+		URL:            r.URL.String(),
+		RequestHeaders: r.Header,
+		Status:         resp.StatusCode,
+		Headers:        resp.Header,
+	}
+	fixture.SetBody(body)
+	if err := t.store.Save(fixture); err != nil {
+		t.store.log.Warn("Failed to record fixture", "error", err)
+	}
+	return resp, nil
+}
+
+var unsafePathChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// path maps a request to <dir>/<host>/<method>-<path>-<hash>.json. The hash
+// covers the redacted URL so recordings don't depend on the API key in use.
+func (s *Store) path(method, rawURL string) string {
+	redactedURL := redactURL(rawURL)
+	u, err := url.Parse(redactedURL)
+	if err != nil {
+		u = &url.URL{Host: "invalid", Path: rawURL}
+	}
+
+	slug := strings.Trim(unsafePathChars.ReplaceAllString(u.Path, "_"), "_")
+	if len(slug) > 80 {
+		slug = slug[len(slug)-80:]
+	}
+	sum := sha256.Sum256([]byte(method + " " + redactedURL))
+	name := fmt.Sprintf("%s-%s-%s.json", strings.ToLower(method), slug, hex.EncodeToString(sum[:4]))
+	return filepath.Join(s.dir, unsafePathChars.ReplaceAllString(u.Host, "_"), name)
+}
+
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := u.Query()
+	changed := false
+	for key := range query {
+		for _, sensitive := range sensitiveParams {
+			if strings.EqualFold(key, sensitive) {
+				query.Set(key, redacted)
+				changed = true
+			}
+		}
+	}
+	if changed {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+func redactHeaders(headers http.Header) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	cleaned := headers.Clone()
+	for _, name := range sensitiveHeaders {
+		if cleaned.Get(name) != "" {
+			cleaned.Set(name, redacted)
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,316 @@
+package guildsettings
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"yk-dc-bot/internal/apperrors"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	KeyRegion              = "region"
+	KeyAnnouncementChannel = "announcement_channel"
+	KeyRankRoles           = "rank_roles"
+	KeyLanguage            = "language"
+	KeyDisabledCommands    = "disabled_commands"
+	KeyEphemeral           = "ephemeral"
+)
+
+// Definition describes a setting an admin can change with /settings. Its
+// description lives in the i18n catalog under settings.descriptions.
+type Definition struct {
+	Key     string
+	Default string
+	// Example is shown when a value is rejected.
+	Example string
+	// normalize validates a value and returns it in its stored form.
+	normalize normalizer
+}
+
+// normalizer validates a value. languages are the locales the bot has
+// messages for.
+type normalizer func(value string, languages []discordgo.Locale) (string, error)
+
+// plain adapts a check that doesn't depend on the bot's languages.
+func plain(check func(value string) (string, error)) normalizer {
+	return func(value string, _ []discordgo.Locale) (string, error) {
+		return check(value)
+	}
+}
+
+var Definitions = []Definition{
+	{
+		Key:       KeyRegion,
+		Example:   "eu",
+		normalize: plain(oneOf(Regions...)),
+	},
+	{
+		Key:       KeyAnnouncementChannel,
+		Example:   "#announcements",
+		normalize: plain(snowflake(`^<#(\d+)>$`)),
+	},
+	{
+		Key:       KeyRankRoles,
+		Example:   "gold=@Gold, diamond=@Diamond",
+		normalize: plain(rankRoles),
+	},
+	{
+		Key:       KeyLanguage,
+		Example:   "de",
+		normalize: locale,
+	},
+	{
+		Key:       KeyDisabledCommands,
+		Example:   "heatmap, economy",
+		normalize: plain(commandList),
+	},
+	{
+		Key:       KeyEphemeral,
+		Default:   "false",
+		Example:   "true",
+		normalize: plain(boolean),
+	},
+}
+
+// Regions are the HenrikDev regions the region setting accepts.
+var Regions = []string{"eu", "na", "ap", "kr", "latam", "br"}
+
+// RankTiers are the tier names rank_roles accepts, lowest first.
+var RankTiers = []string{"iron", "bronze", "silver", "gold", "platinum", "diamond", "ascendant", "immortal", "radiant"}
+
+func Lookup(key string) (Definition, bool) {
+	for _, def := range Definitions {
+		if def.Key == key {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Normalize validates value for key, returning the form it is stored in.
+// languages are the locales the language setting accepts.
+func Normalize(key, value string, languages []discordgo.Locale) (string, error) {
+	def, ok := Lookup(key)
+	if !ok {
+		return "", apperrors.New("GUILD_SETTING_UNKNOWN", fmt.Sprintf("unknown setting %q", key), fmt.Sprintf("there's no setting called %s.", key))
+	}
+	normalized, err := def.normalize(strings.TrimSpace(value), languages)
+	if err != nil {
+		return "", apperrors.Wrap(err, "GUILD_SETTING_INVALID", fmt.Sprintf("invalid value %q for %s", value, key),
+			fmt.Sprintf("that's not a valid %s: %v (e.g. `%s`)", key, err, def.Example))
+	}
+	return normalized, nil
+}
+
+// Settings is a guild's resolved configuration, with defaults filled in.
+type Settings struct {
+	GuildID string
+	// Region is the HenrikDev region lookups use when the command doesn't
+	// pick one. Empty leaves each account's own region.
+	Region              string
+	AnnouncementChannel string
+	// RankRoles maps a tier from RankTiers to a role ID.
+	RankRoles map[string]string
+	// Language is empty unless an admin picked one, in which case it
+	// replaces each member's own Discord language.
+	Language         discordgo.Locale
+	DisabledCommands []string
+	Ephemeral        bool
+
+	// Values holds the overrides as stored, keyed by setting.
+	Values map[string]string
+}
+
+// Defaults are the settings of a guild that hasn't changed anything, and of
+// direct messages.
+func Defaults(guildID string) *Settings {
+	return fromValues(guildID, nil)
+}
+
+func fromValues(guildID string, values map[string]string) *Settings {
+	get := func(key string) string {
+		if value, ok := values[key]; ok {
+			return value
+		}
+		def, _ := Lookup(key)
+		return def.Default
+	}
+
+	settings := &Settings{
+		GuildID:             guildID,
+		Region:              get(KeyRegion),
+		AnnouncementChannel: get(KeyAnnouncementChannel),
+		RankRoles:           make(map[string]string),
+		Language:            discordgo.Locale(get(KeyLanguage)),
+		Values:              values,
+	}
+	settings.Ephemeral, _ = strconv.ParseBool(get(KeyEphemeral))
+	for _, pair := range splitList(get(KeyRankRoles)) {
+		if tier, role, ok := strings.Cut(pair, "="); ok {
+			settings.RankRoles[tier] = role
+		}
+	}
+	settings.DisabledCommands = splitList(get(KeyDisabledCommands))
+	return settings
+}
+
+// RankRole returns the role mapped to the tier of rank, a HenrikDev rank name
+// such as "Diamond 1" or "Radiant".
+func (s *Settings) RankRole(rank string) (string, bool) {
+	tier, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(rank)), " ")
+	role, ok := s.RankRoles[tier]
+	return role, ok
+}
+
+// CommandEnabled reports whether path, e.g. "tracker" or "settings view", may
+// be used in the guild. Disabling a command disables all its subcommands.
+func (s *Settings) CommandEnabled(path string) bool {
+	root, _, _ := strings.Cut(path, " ")
+	for _, disabled := range s.DisabledCommands {
+		if disabled == path || disabled == root {
+			return false
+		}
+	}
+	return true
+}
+
+// Display formats the stored value of key for humans, or "" when unset.
+func (s *Settings) Display(key string) string {
+	value, ok := s.Values[key]
+	if !ok {
+		return ""
+	}
+	switch key {
+	case KeyAnnouncementChannel:
+		return "<#" + value + ">"
+	case KeyRankRoles:
+		parts := make([]string, 0, len(s.RankRoles))
+		for _, tier := range RankTiers {
+			if role, ok := s.RankRoles[tier]; ok {
+				parts = append(parts, fmt.Sprintf("%s <@&%s>", tier, role))
+			}
+		}
+		return strings.Join(parts, ", ")
+	case KeyLanguage:
+		return fmt.Sprintf("%s (%s)", discordgo.Locales[discordgo.Locale(value)], value)
+	default:
+		return value
+	}
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func oneOf(allowed ...string) func(string) (string, error) {
+	return func(value string) (string, error) {
+		value = strings.ToLower(value)
+		for _, option := range allowed {
+			if value == option {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("expected one of %s", strings.Join(allowed, ", "))
+	}
+}
+
+var digits = regexp.MustCompile(`^\d{17,20}$`)
+
+// snowflake accepts an ID or a mention matching pattern and stores the ID.
+func snowflake(pattern string) func(string) (string, error) {
+	mention := regexp.MustCompile(pattern)
+	return func(value string) (string, error) {
+		if match := mention.FindStringSubmatch(value); match != nil {
+			value = match[1]
+		}
+		if !digits.MatchString(value) {
+			return "", fmt.Errorf("expected a mention or an ID")
+		}
+		return value, nil
+	}
+}
+
+var roleID = snowflake(`^<@&(\d+)>$`)
+
+func rankRoles(value string) (string, error) {
+	roles := make(map[string]string)
+	for _, pair := range splitList(value) {
+		tier, role, ok := strings.Cut(pair, "=")
+		if !ok {
+			return "", fmt.Errorf("expected tier=role pairs")
+		}
+		tier = strings.ToLower(strings.TrimSpace(tier))
+		if !slices.Contains(RankTiers, tier) {
+			return "", fmt.Errorf("unknown tier %q, expected one of %s", tier, strings.Join(RankTiers, ", "))
+		}
+		id, err := roleID(strings.TrimSpace(role))
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", tier, err)
+		}
+		roles[tier] = id
+	}
+	if len(roles) == 0 {
+		return "", fmt.Errorf("expected at least one tier=role pair")
+	}
+
+	pairs := make([]string, 0, len(roles))
+	for _, tier := range RankTiers {
+		if id, ok := roles[tier]; ok {
+			pairs = append(pairs, tier+"="+id)
+		}
+	}
+	return strings.Join(pairs, ","), nil
+}
+
+func locale(value string, languages []discordgo.Locale) (string, error) {
+	names := make([]string, 0, len(languages))
+	for _, code := range languages {
+		if strings.EqualFold(string(code), value) {
+			return string(code), nil
+		}
+		names = append(names, string(code))
+	}
+	return "", fmt.Errorf("expected one of %s", strings.Join(names, ", "))
+}
+
+var commandName = regexp.MustCompile(`^[a-z0-9_-]{1,32}( [a-z0-9_-]{1,32}){0,2}$`)
+
+func commandList(value string) (string, error) {
+	var names []string
+	for _, name := range splitList(strings.ToLower(value)) {
+		name = strings.Join(strings.Fields(strings.TrimPrefix(name, "/")), " ")
+		if !commandName.MatchString(name) {
+			return "", fmt.Errorf("%q isn't a command name", name)
+		}
+		if strings.HasPrefix(name, "settings") {
+			return "", fmt.Errorf("/settings can't be disabled")
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ","), nil
+}
+
+func boolean(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return "true", nil
+	case "false", "no", "off", "0":
+		return "false", nil
+	}
+	return "", fmt.Errorf("expected true or false")
+}
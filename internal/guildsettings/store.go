@@ -0,0 +1,198 @@
+package guildsettings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/database"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/models"
+	redisclient "yk-dc-bot/internal/redisclient"
+)
+
+const (
+	// redisTTL bounds how stale the shared cache can get if an invalidation
+	// is lost.
+	redisTTL = time.Hour
+	// localTTL bounds the in-process cache the same way for a missed publish.
+	localTTL = time.Minute
+
+	invalidateChannel = "guild_settings:invalidate"
+)
+
+type cached struct {
+	settings *Settings
+	expires  time.Time
+}
+
+// Store keeps guild settings in Postgres, cached in Redis and in process.
+// Changes publish the guild ID on a Redis channel so every instance drops its
+// in-process copy.
+type Store struct {
+	db          *database.Database
+	redisClient *redisclient.Client
+	catalog     *i18n.Catalog
+	log         *logger.Logger
+
+	mu     sync.RWMutex
+	local  map[string]cached
+	cancel context.CancelFunc
+}
+
+func NewStore(db *database.Database, redisClient *redisclient.Client, catalog *i18n.Catalog, log *logger.Logger) *Store {
+	return &Store{
+		db:          db,
+		redisClient: redisClient,
+		catalog:     catalog,
+		log:         log,
+		local:       make(map[string]cached),
+	}
+}
+
+// Get returns the guild's settings. On a lookup failure it returns the
+// defaults along with the error, so callers can carry on. A nil Store always
+// returns the defaults.
+func (s *Store) Get(ctx context.Context, guildID string) (*Settings, error) {
+	if s == nil || guildID == "" {
+		return Defaults(""), nil
+	}
+
+	s.mu.RLock()
+	entry, ok := s.local[guildID]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.settings, nil
+	}
+
+	values, err := s.values(ctx, guildID)
+	if err != nil {
+		return Defaults(guildID), err
+	}
+
+	settings := fromValues(guildID, values)
+	s.mu.Lock()
+	s.local[guildID] = cached{settings: settings, expires: time.Now().Add(localTTL)}
+	s.mu.Unlock()
+	return settings, nil
+}
+
+func (s *Store) values(ctx context.Context, guildID string) (map[string]string, error) {
+	key := cacheKey(guildID)
+	if data, err := s.redisClient.Get(ctx, key); err == nil {
+		var values map[string]string
+		if err := json.Unmarshal([]byte(data), &values); err == nil {
+			return values, nil
+		}
+	}
+
+	var rows []models.GuildSetting
+	if err := s.db.SelectContext(ctx, &rows, `SELECT name, value FROM guild_settings WHERE guild_id = $1`, guildID); err != nil {
+		return nil, apperrors.Wrap(err, "GUILD_SETTINGS_ERROR", "failed to load settings for guild "+guildID)
+	}
+
+	values := make(map[string]string, len(rows))
+	for _, row := range rows {
+		if _, known := Lookup(row.Name); known {
+			values[row.Name] = row.Value
+		}
+	}
+
+	data, _ := json.Marshal(values)
+	if err := s.redisClient.Set(ctx, key, string(data), redisTTL); err != nil {
+		s.log.Warn("Failed to cache guild settings", "guild", guildID, "error", err)
+	}
+	return values, nil
+}
+
+// Set validates and stores a setting, returning the stored value.
+func (s *Store) Set(ctx context.Context, guildID, key, value, userID string) (string, error) {
+	normalized, err := Normalize(key, value, s.catalog.Locales())
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.db.NamedExecContext(ctx, `
+		INSERT INTO guild_settings (guild_id, name, value, updated_by, updated_at)
+		VALUES (:guild_id, :name, :value, :updated_by, :updated_at)
+		ON CONFLICT (guild_id, name) DO UPDATE
+		SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`, models.GuildSetting{
+		GuildID:   guildID,
+		Name:      key,
+		Value:     normalized,
+		UpdatedBy: userID,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return "", apperrors.Wrap(err, "GUILD_SETTINGS_ERROR", fmt.Sprintf("failed to save %s for guild %s", key, guildID))
+	}
+
+	s.invalidate(ctx, guildID)
+	return normalized, nil
+}
+
+// Reset drops the guild's override for key, or every override when key is
+// empty.
+func (s *Store) Reset(ctx context.Context, guildID, key string) error {
+	var err error
+	if key == "" {
+		_, err = s.db.ExecContext(ctx, `DELETE FROM guild_settings WHERE guild_id = $1`, guildID)
+	} else {
+		_, err = s.db.ExecContext(ctx, `DELETE FROM guild_settings WHERE guild_id = $1 AND name = $2`, guildID, key)
+	}
+	if err != nil {
+		return apperrors.Wrap(err, "GUILD_SETTINGS_ERROR", "failed to reset settings for guild "+guildID)
+	}
+
+	s.invalidate(ctx, guildID)
+	return nil
+}
+
+func (s *Store) invalidate(ctx context.Context, guildID string) {
+	s.evict(guildID)
+	if err := s.redisClient.Del(ctx, cacheKey(guildID)); err != nil {
+		s.log.Warn("Failed to drop cached guild settings", "guild", guildID, "error", err)
+	}
+	if err := s.redisClient.Publish(ctx, invalidateChannel, guildID); err != nil {
+		s.log.Warn("Failed to broadcast guild settings change", "guild", guildID, "error", err)
+	}
+}
+
+func (s *Store) evict(guildID string) {
+	s.mu.Lock()
+	delete(s.local, guildID)
+	s.mu.Unlock()
+}
+
+// Start listens for changes made by other instances. Without the
+// subscription, their changes still show up once localTTL passes.
+func (s *Store) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	messages, err := s.redisClient.Subscribe(ctx, invalidateChannel)
+	if err != nil {
+		s.log.Warn("Guild settings changes from other instances will be picked up late", "error", err)
+		return
+	}
+	go func() {
+		for guildID := range messages {
+			s.evict(guildID)
+		}
+	}()
+}
+
+func (s *Store) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+func cacheKey(guildID string) string {
+	return "guild_settings:" + guildID
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"yk-dc-bot/internal/analysis"
+	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/service"
+	"yk-dc-bot/internal/util"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/fx"
+)
+
+const (
+	defaultAnalyzeMatches = 5
+	maxTradeWindowSeconds = 10
+)
+
+var analyzeModule = fx.Module("analyze", fx.Provide(fx.Private, service.NewAnalysis), fx.Provide(newAnalyzeFeature))
+
+type analyzeFeature struct {
+	analysis *service.Analysis
+}
+
+func newAnalyzeFeature(cfg *config.Config, analysis *service.Analysis) Feature {
+	f := &analyzeFeature{analysis: analysis}
+	minMatches := float64(1)
+	minTradeWindow := float64(1)
+	return newFeature(cfg, "analyze", []*commands.Command{{
+		Name:        "analyze",
+		Description: "Break down a player's KAST, first bloods, trades and clutches",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "username",
+				Description: "The player's Valorant username (e.g., username#tag)",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "matches",
+				Description: "How many recent competitive matches to analyze (default 5)",
+				MinValue:    &minMatches,
+				MaxValue:    10,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "trade_window",
+				Description: "Seconds after a death in which a kill still counts as a trade (default 5)",
+				MinValue:    &minTradeWindow,
+				MaxValue:    maxTradeWindowSeconds,
+			},
+			regionOption(),
+		},
+		Handler: f.handle,
+	}})
+}
+
+func (f *analyzeFeature) handle(ctx *interaction.Ctx) {
+	name, tag, ok := splitRiotID(ctx.String("username"))
+	if !ok {
+		ctx.ReplyEphemeral(ctx.T("common.invalid_riot_id_example", i18n.Vars{"command": "analyze"}))
+		return
+	}
+
+	title := ctx.T("analyze.fetching", i18n.Vars{"player": name + "#" + tag})
+	if err := ctx.Defer(title); err != nil {
+		return
+	}
+
+	matchCount := int(ctx.IntOr("matches", defaultAnalyzeMatches))
+	tradeWindow := time.Duration(ctx.IntOr("trade_window", int64(analysis.DefaultTradeWindow/time.Second))) * time.Second
+	data, err := f.analysis.GetPlayerAnalysis(ctx, name, tag, lookupRegion(ctx), matchCount, tradeWindow, ctx.Progress(title))
+	if err != nil {
+		ctx.Fail(err, "getting player analysis")
+		return
+	}
+
+	m := data.Metrics
+	clutchAttempts, clutchWins := m.ClutchTotals()
+
+	analysisEmbed := util.NewEmbed(util.StyleSuccess, fmt.Sprintf("%s#%s", data.AccountName, data.AccountTag),
+		"> "+ctx.T("analyze.summary", i18n.Vars{"count": m.Matches, "rounds": m.Rounds})).
+		WithColor(util.ColorTeal).
+		WithField(ctx.T("analyze.kda"), fmt.Sprintf("> %d / %d / %d", m.Kills, m.Deaths, m.Assists), true).
+		WithField(ctx.T("analyze.kast"), fmt.Sprintf("> %.1f%%", m.KAST()), true).
+		WithField(ctx.T("analyze.first_kills"), fmt.Sprintf("> %d / %d (%.2f)", m.FirstKills, m.FirstDeaths, m.FirstKillRatio()), true).
+		WithField(ctx.T("analyze.trade_kills"), "> "+ctx.T("analyze.trade_kills_value", i18n.Vars{
+			"kills": m.TradeKills,
+			"rate":  fmt.Sprintf("%.1f", m.TradeKillRate()),
+		}), true).
+		WithField(ctx.T("analyze.traded_deaths"), "> "+ctx.T("analyze.traded_deaths_value", i18n.Vars{
+			"deaths": m.TradedDeaths,
+			"rate":   fmt.Sprintf("%.1f", m.TradedDeathRate()),
+		}), true).
+		WithField(ctx.T("analyze.clutches"), "> "+ctx.T("analyze.clutches_value", i18n.Vars{
+			"won":      clutchWins,
+			"attempts": clutchAttempts,
+		})+formatClutches(m.Clutches), false).
+		WithThumbnail(data.CardURL).
+		WithFooter(interaction.Footer).
+		Build()
+
+	ctx.EditEmbeds(analysisEmbed)
+}
+
+func formatClutches(clutches map[int]*analysis.ClutchRecord) string {
+	if len(clutches) == 0 {
+		return ""
+	}
+
+	sizes := make([]int, 0, len(clutches))
+	for size := range clutches {
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+
+	parts := make([]string, 0, len(sizes))
+	for _, size := range sizes {
+		parts = append(parts, fmt.Sprintf("1v%d %d/%d", size, clutches[size].Wins, clutches[size].Attempts))
+	}
+	return "\n> " + strings.Join(parts, " • ")
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"yk-dc-bot/internal/analysis"
+	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/service"
+	"yk-dc-bot/internal/util"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/fx"
+)
+
+var economyModule = fx.Module("economy", fx.Provide(fx.Private, service.NewEconomy), fx.Provide(newEconomyFeature))
+
+type economyFeature struct {
+	economy *service.Economy
+}
+
+func newEconomyFeature(cfg *config.Config, economy *service.Economy) Feature {
+	f := &economyFeature{economy: economy}
+	minMatch := float64(1)
+	return newFeature(cfg, "economy", []*commands.Command{{
+		Name:        "economy",
+		Description: "Chart a match's round-by-round economy",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "username",
+				Description: "Pick from this player's recent matches (e.g., username#tag)",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "match",
+				Description: "Which recent match to chart, 1 being the latest (default 1)",
+				MinValue:    &minMatch,
+				MaxValue:    10,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "match_id",
+				Description: "Chart a specific match by its ID instead",
+			},
+			regionOption(),
+		},
+		Handler: f.handle,
+		Cooldowns: []commands.Cooldown{
+			{Scope: commands.CooldownUser, Period: 30 * time.Second, Burst: 2},
+		},
+	}})
+}
+
+func (f *economyFeature) handle(ctx *interaction.Ctx) {
+	matchID := ctx.String("match_id")
+
+	var name, tag string
+	if matchID == "" {
+		fullUsername := ctx.String("username")
+		if fullUsername == "" {
+			ctx.ReplyEphemeral(ctx.T("economy.missing_player"))
+			return
+		}
+
+		var ok bool
+		if name, tag, ok = splitRiotID(fullUsername); !ok {
+			ctx.ReplyEphemeral(ctx.T("common.invalid_riot_id"))
+			return
+		}
+	}
+
+	title := ctx.T("economy.fetching")
+	if err := ctx.Defer(title); err != nil {
+		return
+	}
+
+	economy, err := f.economy.GetMatchEconomy(ctx, matchID, name, tag, lookupRegion(ctx), int(ctx.IntOr("match", 1)), ctx.Progress(title))
+	if err != nil {
+		ctx.Fail(err, "getting match economy")
+		return
+	}
+
+	economyEmbed := util.NewEmbed(util.StyleSuccess, ctx.T("economy.title", i18n.Vars{"map": strings.ToLower(economy.Map)}),
+		"> "+ctx.T("economy.score", i18n.Vars{"red": economy.RedScore, "blue": economy.BlueScore})).
+		WithColor(util.ColorGold).
+		WithField(ctx.T("economy.red_buys"), "> "+formatBuySummary(ctx, economy.Rounds, "Red"), false).
+		WithField(ctx.T("economy.blue_buys"), "> "+formatBuySummary(ctx, economy.Rounds, "Blue"), false).
+		WithImage("attachment://economy.png").
+		WithFooter(ctx.T("economy.footer", i18n.Vars{"id": economy.MatchID})).
+		Build()
+
+	ctx.Edit(&discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{economyEmbed},
+		Files: []*discordgo.File{
+			{Name: "economy.png", ContentType: "image/png", Reader: bytes.NewReader(economy.Image)},
+		},
+	})
+}
+
+func formatBuySummary(ctx *interaction.Ctx, rounds []analysis.RoundEconomy, team string) string {
+	played := make(map[string]int)
+	won := make(map[string]int)
+	for _, r := range rounds {
+		buy := r.Blue.Buy
+		if team == "Red" {
+			buy = r.Red.Buy
+		}
+		played[buy]++
+		if strings.EqualFold(r.Winner, team) {
+			won[buy]++
+		}
+	}
+
+	parts := make([]string, 0, 4)
+	for _, buy := range []string{analysis.BuyFull, analysis.BuyForce, analysis.BuyEco, analysis.BuyPistol} {
+		if played[buy] == 0 {
+			continue
+		}
+		parts = append(parts, ctx.T("economy.buy", i18n.Vars{
+			"buy":    ctx.T("economy.buys." + buy),
+			"played": played[buy],
+			"won":    won[buy],
+		}))
+	}
+	return strings.Join(parts, " • ")
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/errorreport"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/util"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/fx"
+)
+
+var errorsModule = fx.Module("errors", fx.Provide(newErrorsFeature))
+
+// maxErrorGroups keeps the list within an embed's field limit.
+const maxErrorGroups = 10
+
+type errorsFeature struct {
+	reporter *errorreport.Reporter
+}
+
+func newErrorsFeature(cfg *config.Config, reporter *errorreport.Reporter) Feature {
+	f := &errorsFeature{reporter: reporter}
+	minHours := float64(1)
+	return newFeature(cfg, "errors", []*commands.Command{{
+		Name:        "errors",
+		Description: "Show recently reported errors (bot owners only)",
+		Middlewares: []commands.Middleware{commands.OwnerOnly(cfg)},
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "reference",
+				Description: "Show the errors behind a reference from an error message",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "hours",
+				Description: "How far back to look (default 24)",
+				MinValue:    &minHours,
+				MaxValue:    24 * 30,
+			},
+		},
+		Handler: f.handle,
+	}})
+}
+
+func (f *errorsFeature) handle(ctx *interaction.Ctx) {
+	// accept the reference as copied from an embed footer, e.g. "ref 3fa9c01b2e7d"
+	if fields := strings.Fields(ctx.String("reference")); len(fields) > 0 {
+		f.showReference(ctx, strings.ToLower(fields[len(fields)-1]))
+		return
+	}
+
+	hours := ctx.IntOr("hours", 24)
+	summaries, err := f.reporter.Recent(ctx, time.Duration(hours)*time.Hour, maxErrorGroups)
+	if err != nil {
+		f.reject(ctx, err, "loading recent errors")
+		return
+	}
+
+	description := ""
+	if len(summaries) == 0 {
+		description = "> " + ctx.T("errorlog.none", i18n.Vars{"count": hours})
+	}
+	builder := util.NewEmbed(util.StyleDefault, ctx.T("errorlog.title"), description).
+		WithColor(util.ColorRed).
+		WithFooter(interaction.Footer)
+	for _, summary := range summaries {
+		builder = builder.WithField(
+			fmt.Sprintf("%s ×%d", summary.Code, summary.Count),
+			fmt.Sprintf("> %s\n-# /%s • %s • ref `%s`",
+				truncate(summary.Message, 200),
+				summary.Command,
+				ctx.T("errorlog.last_seen", i18n.Vars{"when": fmt.Sprintf("<t:%d:R>", summary.LastSeen.Unix())}),
+				summary.CorrelationID,
+			),
+			false,
+		)
+	}
+
+	ctx.Respond(util.InteractionResponse{Embeds: []*discordgo.MessageEmbed{builder.Build()}, Ephemeral: true})
+}
+
+func (f *errorsFeature) showReference(ctx *interaction.Ctx, reference string) {
+	occurrences, err := f.reporter.ByReference(ctx, reference)
+	if err != nil {
+		f.reject(ctx, err, "loading errors for a reference")
+		return
+	}
+	if len(occurrences) == 0 {
+		ctx.ReplyEphemeral(ctx.T("errorlog.reference_none", i18n.Vars{"id": reference}))
+		return
+	}
+
+	builder := util.NewEmbed(util.StyleDefault, ctx.T("errorlog.reference_title", i18n.Vars{"id": reference}), "").
+		WithColor(util.ColorRed).
+		WithFooter(interaction.Footer)
+	for _, o := range occurrences {
+		chain := strings.ReplaceAll(o.Chain, "\n", "\n↳ ")
+		builder = builder.WithField(o.Code, fmt.Sprintf("```\n%s\n```\n-# /%s • %s • <@%s> • <t:%d:f>",
+			truncate(chain, 800), o.Command, o.GuildID, o.UserID, o.OccurredAt.Unix()), false)
+	}
+
+	ctx.Respond(util.InteractionResponse{Embeds: []*discordgo.MessageEmbed{builder.Build()}, Ephemeral: true})
+}
+
+func (f *errorsFeature) reject(ctx *interaction.Ctx, err error, action string) {
+	errorMessage, logMessage := ctx.ErrorMessage(err, action)
+	ctx.Log.Warn(logMessage)
+	ctx.RespondError(errorMessage)
+}
+
+// truncate shortens s to at most max runes.
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "…"
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/service"
+	"yk-dc-bot/internal/util"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/fx"
+)
+
+var h2hModule = fx.Module("h2h", fx.Provide(fx.Private, service.NewHeadToHead), fx.Provide(newHeadToHeadFeature))
+
+type headToHeadFeature struct {
+	h2h *service.HeadToHead
+}
+
+func newHeadToHeadFeature(cfg *config.Config, h2h *service.HeadToHead) Feature {
+	f := &headToHeadFeature{h2h: h2h}
+	return newFeature(cfg, "h2h", []*commands.Command{{
+		Name:        "h2h",
+		Description: "Compare two players' shared match history",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "player_a",
+				Description: "The first player's Valorant username (e.g., username#tag)",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "player_b",
+				Description: "The second player's Valorant username (e.g., username#tag)",
+				Required:    true,
+			},
+			regionOption(),
+		},
+		Handler: f.handle,
+	}})
+}
+
+func (f *headToHeadFeature) handle(ctx *interaction.Ctx) {
+	nameA, tagA, okA := splitRiotID(ctx.String("player_a"))
+	nameB, tagB, okB := splitRiotID(ctx.String("player_b"))
+	if !okA || !okB {
+		ctx.ReplyEphemeral(ctx.T("h2h.invalid"))
+		return
+	}
+
+	title := ctx.T("h2h.fetching", i18n.Vars{"a": nameA + "#" + tagA, "b": nameB + "#" + tagB})
+	if err := ctx.Defer(title); err != nil {
+		return
+	}
+
+	h2h, err := f.h2h.GetHeadToHead(ctx, nameA, tagA, nameB, tagB, lookupRegion(ctx), ctx.Progress(title))
+	if err != nil {
+		ctx.Fail(err, "getting head-to-head")
+		return
+	}
+
+	total := h2h.Together.Games + h2h.Against.Games
+	builder := util.NewEmbed(util.StyleSuccess, ctx.T("h2h.title", i18n.Vars{"a": h2h.AName, "b": h2h.BName}),
+		"> "+ctx.T("h2h.shared", i18n.Vars{"count": total})).
+		WithColor(util.ColorPink)
+
+	if total == 0 {
+		builder = builder.WithField(ctx.T("h2h.no_shared"), "> "+ctx.T("h2h.no_shared_value"), false)
+	} else {
+		builder = builder.
+			WithField(ctx.T("h2h.together"), formatHeadToHeadGroup(ctx, h2h.Together, h2h.AName, h2h.BName, true), false).
+			WithField(ctx.T("h2h.against"), formatHeadToHeadGroup(ctx, h2h.Against, h2h.AName, h2h.BName, false), false)
+
+		recent := make([]string, 0, len(h2h.Recent))
+		for _, match := range h2h.Recent {
+			relation := ctx.T("h2h.against")
+			if match.SameTeam {
+				relation = ctx.T("h2h.together")
+			}
+			result := ctx.T("h2h.lost")
+			if match.AWon {
+				result = ctx.T("h2h.won")
+			}
+			recent = append(recent, fmt.Sprintf("> %s • %s • %s %s • <t:%d:R>",
+				strings.ToLower(match.Map), relation, h2h.AName, result, match.StartedAt.Unix()))
+		}
+		builder = builder.WithField(ctx.T("h2h.recent"), strings.Join(recent, "\n"), false)
+	}
+
+	ctx.EditEmbeds(builder.WithFooter(interaction.Footer).Build())
+}
+
+func formatHeadToHeadGroup(ctx *interaction.Ctx, group service.HeadToHeadGroup, nameA, nameB string, together bool) string {
+	if group.Games == 0 {
+		return "> " + ctx.T("common.no_games")
+	}
+
+	games := float64(group.Games)
+	vars := i18n.Vars{
+		"count":  group.Games,
+		"rate":   fmt.Sprintf("%.0f", float64(group.AWins)/games*100),
+		"player": nameA,
+		"wins":   group.AWins,
+	}
+	header := "> " + ctx.T("h2h.together_header", vars)
+	if !together {
+		header = "> " + ctx.T("h2h.against_header", vars)
+	}
+
+	line := func(name string, l service.HeadToHeadLine) string {
+		return "> " + ctx.T("h2h.averages", i18n.Vars{
+			"player":  name,
+			"kills":   fmt.Sprintf("%.1f", float64(l.Kills)/games),
+			"deaths":  fmt.Sprintf("%.1f", float64(l.Deaths)/games),
+			"assists": fmt.Sprintf("%.1f", float64(l.Assists)/games),
+			"score":   fmt.Sprintf("%.0f", float64(l.Score)/games),
+		})
+	}
+
+	return strings.Join([]string{header, line(nameA, group.A), line(nameB, group.B)}, "\n")
+}
@@ -1,33 +1,77 @@
 package handlers
 
 import (
+	"strings"
+
+	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/components"
 	"yk-dc-bot/internal/config"
-	"yk-dc-bot/internal/logger"
-	"yk-dc-bot/internal/service"
+	"yk-dc-bot/internal/guildsettings"
+	"yk-dc-bot/internal/interaction"
 
 	"github.com/bwmarrin/discordgo"
+	"go.uber.org/fx"
+)
+
+// Module provides every feature's commands and component routes. Each feature
+// is its own fx module with its own dependencies; DISABLED_FEATURES leaves a
+// feature's commands and routes out of the registry and router.
+var Module = fx.Module("handlers",
+	analyzeModule,
+	economyModule,
+	errorsModule,
+	h2hModule,
+	heatmapModule,
+	matchesModule,
+	rankModule,
+	settingsModule,
+	teammatesModule,
+	trackerModule,
 )
 
-type CommandHandler struct {
-	Name                     string
-	Handler                  func(*discordgo.Session, *discordgo.InteractionCreate, *service.Service, *logger.Logger, *config.Config)
-	Options                  []discordgo.ApplicationCommandOption
-	DefaultMemberPermissions *int64
-	DMPermission             *bool
+// Feature is what a feature module contributes to the command registry and
+// the component router.
+type Feature struct {
+	fx.Out
+
+	Commands []*commands.Command `group:"commands,flatten"`
+	Routes   []*components.Route `group:"components,flatten"`
 }
 
-type ModalHandler struct {
-	CustomID string
-	Handler  func(*discordgo.Session, *discordgo.InteractionCreate, *service.Service, *logger.Logger, *config.Config)
+func newFeature(cfg *config.Config, name string, cmds []*commands.Command, routes ...*components.Route) Feature {
+	if !cfg.FeatureEnabled(name) {
+		return Feature{}
+	}
+	return Feature{Commands: cmds, Routes: routes}
 }
 
-type AutocompleteHandler struct {
-	Name    string
-	Handler func(*discordgo.Session, *discordgo.InteractionCreate, *service.Service, *logger.Logger, *config.Config)
+func splitRiotID(fullUsername string) (string, string, bool) {
+	parts := strings.Split(fullUsername, "#")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
 }
 
-var (
-	CommandHandlers      []CommandHandler
-	ModalHandlers        []ModalHandler
-	AutocompleteHandlers []AutocompleteHandler
-)
+// regionOption lets a HenrikDev lookup pick the region to query.
+func regionOption() *discordgo.ApplicationCommandOption {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(guildsettings.Regions))
+	for _, region := range guildsettings.Regions {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: region, Value: region})
+	}
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "region",
+		Description: "The region to look the player up in (defaults to the server's region)",
+		Choices:     choices,
+	}
+}
+
+// lookupRegion is the region the command picked, else the guild's default.
+// Empty leaves each account's own region.
+func lookupRegion(ctx *interaction.Ctx) string {
+	if region := ctx.String("region"); region != "" {
+		return region
+	}
+	return ctx.Guild.Region
+}
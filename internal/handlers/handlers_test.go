@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"testing"
+
+	"yk-dc-bot/internal/circuit"
+	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/components"
+	"yk-dc-bot/internal/guildsettings"
+	"yk-dc-bot/internal/henrikapi"
+	"yk-dc-bot/internal/testkit"
+	"yk-dc-bot/internal/trngg"
+)
+
+// replayBot runs features end to end against the fake Discord and Redis, with
+// the real API clients replaying the testkit fixtures. Schemas are strict, so
+// a fixture that drifted from what the clients read fails the test.
+type replayBot struct {
+	*testkit.Bot
+	Redis   *testkit.Redis
+	Henrik  *henrikapi.HenrikDevAPI
+	Tracker *trngg.TrackerAPI
+	Codec   *components.Codec
+}
+
+func newReplayBot(t *testing.T) *replayBot {
+	t.Helper()
+	cfg := testkit.ReplayConfig()
+	cfg.ComponentSecret = "testkit"
+
+	bot := testkit.NewBot(t, cfg)
+	redis := testkit.NewRedis(t)
+	client := redis.Client(t)
+	store := testkit.Fixtures(t)
+	schemas := testkit.StrictSchemas()
+	breakers := circuit.NewBreakers()
+
+	// settings are served from the cache; there's no database behind the store
+	redis.Set("guild_settings:"+testkit.GuildID, "{}")
+	bot.Guilds = guildsettings.NewStore(nil, client, bot.Catalog, bot.Log)
+
+	return &replayBot{
+		Bot:     bot,
+		Redis:   redis,
+		Henrik:  henrikapi.NewHenrikDevAPI(cfg, client, bot.Log, store, schemas, breakers, nil),
+		Tracker: trngg.NewTrackerAPI(cfg, client, bot.Log, store, schemas, breakers, nil),
+		Codec:   components.NewCodec(cfg, client, bot.Log),
+	}
+}
+
+// install registers the features' commands and component routes the way the
+// fx groups do in the bot. Run adds the bot's global middlewares.
+func (b *replayBot) install(features ...Feature) {
+	var cmds []*commands.Command
+	var routes []*components.Route
+	for _, feature := range features {
+		cmds = append(cmds, feature.Commands...)
+		routes = append(routes, feature.Routes...)
+	}
+	b.Commands = commands.NewRegistry(commands.RegistryParams{Commands: cmds, Catalog: b.Catalog})
+	b.Router = components.NewRouter(components.RouterParams{Codec: b.Codec, Routes: routes})
+}
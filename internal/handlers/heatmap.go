@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"yk-dc-bot/internal/analysis"
+	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/service"
+	"yk-dc-bot/internal/util"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/fx"
+)
+
+var heatmapMaps = []string{"Abyss", "Ascent", "Bind", "Breeze", "Fracture", "Haven", "Icebox", "Lotus", "Pearl", "Split", "Sunset"}
+
+var heatmapModule = fx.Module("heatmap", fx.Provide(fx.Private, service.NewHeatmap), fx.Provide(newHeatmapFeature))
+
+type heatmapFeature struct {
+	heatmap *service.Heatmap
+}
+
+func newHeatmapFeature(cfg *config.Config, heatmap *service.Heatmap) Feature {
+	f := &heatmapFeature{heatmap: heatmap}
+
+	mapChoices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(heatmapMaps))
+	for _, name := range heatmapMaps {
+		mapChoices = append(mapChoices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: name})
+	}
+
+	return newFeature(cfg, "heatmap", []*commands.Command{{
+		Name:        "heatmap",
+		Description: "Render a player's kill and death heatmap on a map",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "username",
+				Description: "The player's Valorant username (e.g., username#tag)",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "map",
+				Description: "The map to plot",
+				Required:    true,
+				Choices:     mapChoices,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "side",
+				Description: "Only include rounds on this side",
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "attack", Value: analysis.SideAttack},
+					{Name: "defense", Value: analysis.SideDefense},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "agent",
+				Description: "Only include matches played on this agent (e.g., Jett)",
+			},
+			regionOption(),
+		},
+		Handler: f.handle,
+		Cooldowns: []commands.Cooldown{
+			{Scope: commands.CooldownUser, Period: 30 * time.Second, Burst: 2},
+		},
+	}})
+}
+
+func (f *heatmapFeature) handle(ctx *interaction.Ctx) {
+	filter := analysis.PositionFilter{
+		Map:   ctx.String("map"),
+		Side:  ctx.String("side"),
+		Agent: ctx.String("agent"),
+	}
+
+	name, tag, ok := splitRiotID(ctx.String("username"))
+	if !ok || filter.Map == "" {
+		ctx.ReplyEphemeral(ctx.T("heatmap.invalid"))
+		return
+	}
+
+	title := ctx.T("heatmap.fetching", i18n.Vars{"map": strings.ToLower(filter.Map), "player": name + "#" + tag})
+	if err := ctx.Defer(title); err != nil {
+		return
+	}
+
+	heatmap, err := f.heatmap.GetPlayerHeatmap(ctx, name, tag, lookupRegion(ctx), filter, ctx.Progress(title))
+	if err != nil {
+		ctx.Fail(err, "getting player heatmap")
+		return
+	}
+
+	filters := []string{ctx.T("common.matches", i18n.Vars{"count": heatmap.Matches})}
+	if filter.Side != "" {
+		filters = append(filters, ctx.T("heatmap.sides."+filter.Side))
+	}
+	if filter.Agent != "" {
+		filters = append(filters, strings.ToLower(filter.Agent))
+	}
+
+	heatmapEmbed := util.NewEmbed(util.StyleSuccess, ctx.T("heatmap.title", i18n.Vars{
+		"player": heatmap.AccountName + "#" + heatmap.AccountTag,
+		"map":    strings.ToLower(heatmap.Map),
+	}),
+		"> "+strings.Join(filters, " • ")).
+		WithColor(util.ColorPurple).
+		WithField(ctx.T("heatmap.kills"), fmt.Sprintf("> %d", heatmap.Kills), true).
+		WithField(ctx.T("heatmap.deaths"), fmt.Sprintf("> %d", heatmap.Deaths), true).
+		WithImage("attachment://heatmap.png").
+		WithFooter(interaction.Footer).
+		Build()
+
+	ctx.Edit(&discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{heatmapEmbed},
+		Files: []*discordgo.File{
+			{Name: "heatmap.png", ContentType: "image/png", Reader: bytes.NewReader(heatmap.Image)},
+		},
+	})
+}
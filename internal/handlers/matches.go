@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/service"
+	"yk-dc-bot/internal/util"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/fx"
+)
+
+var matchesModule = fx.Module("matches", fx.Provide(fx.Private, service.NewMatches), fx.Provide(newMatchesFeature))
+
+type matchesFeature struct {
+	matches *service.Matches
+}
+
+func newMatchesFeature(cfg *config.Config, matches *service.Matches) Feature {
+	f := &matchesFeature{matches: matches}
+	minMatches := float64(1)
+	return newFeature(cfg, "matches", []*commands.Command{{
+		Name:        "matches",
+		Description: "List a player's recent competitive matches",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "username",
+				Description: "The player's Valorant username (e.g., username#tag)",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "count",
+				Description: "How many matches to list (default 5)",
+				MinValue:    &minMatches,
+				MaxValue:    10,
+			},
+			regionOption(),
+		},
+		Handler: f.handle,
+	}})
+}
+
+func (f *matchesFeature) handle(ctx *interaction.Ctx) {
+	name, tag, ok := splitRiotID(ctx.String("username"))
+	if !ok {
+		ctx.ReplyEphemeral(ctx.T("common.invalid_riot_id"))
+		return
+	}
+
+	title := ctx.T("matches.fetching", i18n.Vars{"player": name + "#" + tag})
+	if err := ctx.Defer(title); err != nil {
+		return
+	}
+
+	history, err := f.matches.GetPlayerMatches(ctx, name, tag, lookupRegion(ctx), int(ctx.IntOr("count", 5)), ctx.Progress(title))
+	if err != nil {
+		ctx.Fail(err, "getting player matches")
+		return
+	}
+
+	builder := util.NewEmbed(util.StyleSuccess, ctx.T("matches.title", i18n.Vars{"player": history.AccountName + "#" + history.AccountTag}), "").
+		WithColor(util.ColorBlue)
+
+	if len(history.Matches) == 0 {
+		builder = builder.WithField(ctx.T("matches.none"), "> "+ctx.T("matches.none_value"), false)
+	}
+
+	for _, match := range history.Matches {
+		result := ctx.T("matches.loss")
+		if match.Won {
+			result = ctx.T("matches.win")
+		} else if match.TeamScore == match.EnemyScore {
+			result = ctx.T("matches.draw")
+		}
+
+		queue := ctx.T("matches.solo")
+		if match.PartySize > 1 {
+			queue = ctx.T("matches.premade", i18n.Vars{"size": match.PartySize})
+		}
+
+		builder = builder.WithField(
+			fmt.Sprintf("%s • %s • %d-%d %s", strings.ToLower(match.Map), strings.ToLower(match.Agent), match.TeamScore, match.EnemyScore, result),
+			fmt.Sprintf("> %d / %d / %d • %s • <t:%d:R>", match.Kills, match.Deaths, match.Assists, queue, match.StartedAt.Unix()),
+			false,
+		)
+	}
+
+	ctx.EditEmbeds(builder.WithFooter(interaction.Footer).Build())
+}
@@ -2,96 +2,109 @@ package handlers
 
 import (
 	"fmt"
-	"strings"
+	"time"
 
-	"yk-dc-bot/internal/apperrors"
 	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/components"
 	"yk-dc-bot/internal/config"
-	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
 	"yk-dc-bot/internal/service"
 	"yk-dc-bot/internal/util"
 
 	"github.com/bwmarrin/discordgo"
+	"go.uber.org/fx"
 )
 
-func init() {
-	commands.AddRegistration(func() {
-		commands.Register(&commands.Command{
-			Name:        "rank",
-			Description: "Get a player's Valorant rank",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "username",
-					Description: "The player's Valorant username (e.g., username#tag)",
-					Required:    true,
-				},
+var rankModule = fx.Module("rank", fx.Provide(fx.Private, service.NewRank), fx.Provide(newRankFeature))
+
+type rankFeature struct {
+	rank    *service.Rank
+	codec   *components.Codec
+	refresh *components.Route
+}
+
+func newRankFeature(cfg *config.Config, rank *service.Rank, codec *components.Codec) Feature {
+	f := &rankFeature{rank: rank, codec: codec}
+	f.refresh = &components.Route{
+		Pattern: "rank:refresh:{name}:{tag}",
+		Handler: f.handleRefresh,
+		Signed:  true,
+		TTL:     24 * time.Hour,
+	}
+
+	return newFeature(cfg, "rank", []*commands.Command{{
+		Name:        "rank",
+		Description: "Get a player's Valorant rank",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "username",
+				Description: "The player's Valorant username (e.g., username#tag)",
+				Required:    true,
 			},
-			Handler: handleRankCommand,
-		})
-	})
+			regionOption(),
+		},
+		Handler: f.handle,
+	}}, f.refresh)
 }
 
-func handleRankCommand(s *discordgo.Session, i *discordgo.InteractionCreate, svc *service.Service, log *logger.Logger, cfg *config.Config) {
-	options := i.ApplicationCommandData().Options
-	if len(options) < 1 {
-		util.RespondToInteraction(s, i, util.InteractionResponse{
-			Content:   "please provide a valid valorant username and tag (e.g., /rank username#tag)",
-			Ephemeral: true,
-		})
+func (f *rankFeature) handle(ctx *interaction.Ctx) {
+	name, tag, ok := splitRiotID(ctx.String("username"))
+	if !ok {
+		ctx.ReplyEphemeral(ctx.T("common.invalid_riot_id_example", i18n.Vars{"command": "rank"}))
 		return
 	}
 
-	fullUsername := options[0].StringValue()
-	parts := strings.Split(fullUsername, "#")
-	if len(parts) != 2 {
-		util.RespondToInteraction(s, i, util.InteractionResponse{
-			Content:   "invalid riot id. please use the username#tag format",
-			Ephemeral: true,
-		})
+	if err := ctx.Defer(ctx.T("rank.fetching", i18n.Vars{"player": name + "#" + tag})); err != nil {
 		return
 	}
 
-	name, tag := parts[0], parts[1]
+	f.send(ctx, name, tag)
+}
 
-	err := util.DeferResponse(s, i, util.DeferResponseOptions{
-		Ephemeral:     false,
-		CustomContent: "",
-		Embeds: []*discordgo.MessageEmbed{
-			util.NewEmbed(util.StyleDefault, fmt.Sprintf("fetching rank for %s#%s", name, tag), "> please wait a moment").
-				WithFooter("valorant integration").
-				Build(),
-		},
-	})
-	if err != nil {
-		log.Error("Error deferring response", "error", err)
+func (f *rankFeature) handleRefresh(ctx *interaction.Ctx, args components.Args) {
+	if err := ctx.DeferUpdate(); err != nil {
 		return
 	}
 
-	tracker := util.NewProgressTracker(s, i.Interaction, fmt.Sprintf("fetching rank for %s#%s", name, tag), "valorant integration", util.StyleDefault)
-	tracker.Start()
+	f.send(ctx, args.String("name"), args.String("tag"))
+}
 
-	rankData, err := svc.GetPlayerRankData(name, tag, tracker)
+func (f *rankFeature) send(ctx *interaction.Ctx, name, tag string) {
+	rankData, err := f.rank.GetPlayerRankData(ctx, name, tag, lookupRegion(ctx), ctx.Progress(ctx.T("rank.fetching", i18n.Vars{"player": name + "#" + tag})))
 	if err != nil {
-		errorMessage, logMessage := apperrors.HandleError(err, "getting player rank data")
-		log.Error(logMessage)
-		util.SendErrorEmbed(s, i.Interaction, errorMessage, log, "valorant integration")
+		ctx.Fail(err, "getting player rank data")
 		return
 	}
 
-	rankEmbed := util.NewEmbed(util.StyleSuccess, fmt.Sprintf("%s#%s", rankData.AccountName, rankData.AccountTag), "").
+	builder := util.NewEmbed(util.StyleSuccess, fmt.Sprintf("%s#%s", rankData.AccountName, rankData.AccountTag), "").
 		WithColor(util.ColorGold).
-		WithField("rank", "> "+rankData.Rank, false).
-		WithField("ranked rating", "> "+fmt.Sprintf("%d/100", rankData.RR), false).
-		WithField("last game", "> "+fmt.Sprintf("%+d rr", rankData.LastGameRR), false).
+		WithField(ctx.T("rank.rank"), "> "+rankData.Rank, false).
+		WithField(ctx.T("rank.ranked_rating"), "> "+fmt.Sprintf("%d/100", rankData.RR), false).
+		WithField(ctx.T("rank.last_game"), "> "+ctx.T("rank.last_game_value", i18n.Vars{"change": fmt.Sprintf("%+d", rankData.LastGameRR)}), false)
+	if role, ok := ctx.Guild.RankRole(rankData.Rank); ok {
+		builder = builder.WithField(ctx.T("rank.role"), "> <@&"+role+">", false)
+	}
+	rankEmbed := builder.
 		WithThumbnail(rankData.CardURL).
-		WithFooter("valorant integration").
+		WithFooter(interaction.Footer).
 		Build()
 
-	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+	edit := &discordgo.WebhookEdit{
 		Embeds: &[]*discordgo.MessageEmbed{rankEmbed},
-	})
+	}
+
+	refreshID, err := f.codec.CustomID(f.refresh, rankData.AccountName, rankData.AccountTag)
 	if err != nil {
-		log.Error("Error editing final interaction response", "error", apperrors.Wrap(err, "INTERACTION_EDIT_ERROR", "failed to edit interaction response"))
+		ctx.Log.Warn("Error building refresh button", "error", err)
+	} else {
+		edit.Components = &[]discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: ctx.T("common.refresh"), Style: discordgo.SecondaryButton, CustomID: refreshID},
+			}},
+		}
 	}
+
+	ctx.Edit(edit)
 }
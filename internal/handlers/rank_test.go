@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"yk-dc-bot/internal/service"
+	"yk-dc-bot/internal/testkit"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func newRankBot(t *testing.T) *replayBot {
+	bot := newReplayBot(t)
+	bot.install(newRankFeature(bot.Config, service.NewRank(bot.Henrik), bot.Codec))
+	return bot
+}
+
+func TestRank(t *testing.T) {
+	bot := newRankBot(t)
+
+	i := testkit.Command("rank").String("username", "tester#fake").Build()
+	bot.Run(t, i)
+
+	embed := bot.Discord.FinalEmbed(t, i)
+	testkit.AssertTitle(t, embed, "tester#fake")
+	testkit.AssertField(t, embed, "rank", "Diamond 1")
+	testkit.AssertField(t, embed, "ranked rating", "42/100")
+	testkit.AssertField(t, embed, "last game", "+18 rr")
+	if embed.Thumbnail == nil || !strings.Contains(embed.Thumbnail.URL, "9fb348bc-41a0-91ad-8a3e-818035c4e561") {
+		t.Errorf("thumbnail = %+v, want the player card", embed.Thumbnail)
+	}
+
+	if _, ok := bot.Redis.Get("mmr:eu:fake-puuid-tester"); !ok {
+		t.Error("rank wasn't cached")
+	}
+}
+
+func TestRankUsesGuildRegionAndRankRoles(t *testing.T) {
+	bot := newRankBot(t)
+	bot.Redis.Set("guild_settings:"+testkit.GuildID, `{"region":"na","rank_roles":"gold=300000000000000001,diamond=300000000000000002"}`)
+
+	i := testkit.Command("rank").String("username", "tester#fake").Build()
+	bot.Run(t, i)
+
+	embed := bot.Discord.FinalEmbed(t, i)
+	testkit.AssertField(t, embed, "rank", "Gold 3")
+	testkit.AssertField(t, embed, "server role", "<@&300000000000000001>")
+	if _, ok := bot.Redis.Get("mmr:na:fake-puuid-tester"); !ok {
+		t.Error("rank wasn't looked up in the guild's region")
+	}
+}
+
+func TestRankRegionOptionOverridesGuild(t *testing.T) {
+	bot := newRankBot(t)
+	bot.Redis.Set("guild_settings:"+testkit.GuildID, `{"region":"na"}`)
+
+	i := testkit.Command("rank").String("username", "tester#fake").String("region", "eu").Build()
+	bot.Run(t, i)
+
+	embed := bot.Discord.FinalEmbed(t, i)
+	testkit.AssertField(t, embed, "rank", "Diamond 1")
+	if _, ok := testkit.Field(embed, "server role"); ok {
+		t.Error("rank shows a server role the guild never mapped")
+	}
+}
+
+func TestRankDisabledInGuild(t *testing.T) {
+	bot := newRankBot(t)
+	bot.Redis.Set("guild_settings:"+testkit.GuildID, `{"disabled_commands":"rank"}`)
+
+	i := testkit.Command("rank").String("username", "tester#fake").Build()
+	bot.Run(t, i)
+
+	bot.Discord.AssertReply(t, i, "/rank is turned off in this server", true)
+	if _, ok := bot.Redis.Get("mmr:eu:fake-puuid-tester"); ok {
+		t.Error("disabled command still looked up the rank")
+	}
+}
+
+func TestRankRefreshButton(t *testing.T) {
+	bot := newRankBot(t)
+
+	i := testkit.Command("rank").String("username", "tester#fake").Build()
+	bot.Run(t, i)
+
+	refresh := refreshButton(t, bot.Discord.Message(i))
+	click := testkit.Component(refresh.CustomID).Build()
+	bot.Run(t, click)
+
+	if types := bot.Discord.ResponseTypes(click); len(types) != 1 || types[0] != discordgo.InteractionResponseDeferredMessageUpdate {
+		t.Errorf("refresh responded with %v, want a deferred update", types)
+	}
+	testkit.AssertField(t, bot.Discord.FinalEmbed(t, click), "rank", "Diamond 1")
+}
+
+func TestRankUnknownPlayer(t *testing.T) {
+	bot := newRankBot(t)
+
+	i := testkit.Command("rank").String("username", "nobody#fake").Build()
+	bot.Run(t, i)
+
+	bot.Discord.AssertError(t, i, "Account with this Riot ID not found")
+}
+
+func TestRankSchemaDrift(t *testing.T) {
+	bot := newRankBot(t)
+
+	// the drifted account lacks its region, which strict schemas refuse
+	i := testkit.Command("rank").String("username", "drifted#fake").Build()
+	bot.Run(t, i)
+
+	embed := bot.Discord.FinalEmbed(t, i)
+	if embed.Title != "Error" {
+		t.Fatalf("final embed is %q, want an error", embed.Title)
+	}
+}
+
+func TestRankInvalidRiotID(t *testing.T) {
+	bot := newRankBot(t)
+
+	i := testkit.Command("rank").String("username", "no-tag").Build()
+	bot.Run(t, i)
+
+	bot.Discord.AssertReply(t, i, "/rank", true)
+	if calls := len(bot.Discord.Calls()); calls != 1 {
+		t.Errorf("made %d Discord calls, want just the reply", calls)
+	}
+}
+
+func refreshButton(t *testing.T, message *discordgo.Message) discordgo.Button {
+	t.Helper()
+	if message == nil {
+		t.Fatal("no response to refresh")
+	}
+	for _, component := range message.Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, c := range row.Components {
+			if button, ok := c.(*discordgo.Button); ok {
+				return *button
+			}
+		}
+	}
+	t.Fatalf("response has no button: %+v", message.Components)
+	return discordgo.Button{}
+}
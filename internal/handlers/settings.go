@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"fmt"
+
+	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/guildsettings"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/util"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/fx"
+)
+
+var settingsModule = fx.Module("settings", fx.Provide(newSettingsFeature))
+
+type settingsFeature struct {
+	store *guildsettings.Store
+}
+
+func newSettingsFeature(cfg *config.Config, store *guildsettings.Store) Feature {
+	f := &settingsFeature{store: store}
+
+	keys := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(guildsettings.Definitions))
+	for _, def := range guildsettings.Definitions {
+		keys = append(keys, &discordgo.ApplicationCommandOptionChoice{Name: def.Key, Value: def.Key})
+	}
+
+	permissions := int64(discordgo.PermissionManageServer)
+	dmPermission := false
+	return newFeature(cfg, "settings", []*commands.Command{{
+		Name:                     "settings",
+		Description:              "View or change the bot's settings for this server",
+		DefaultMemberPermissions: &permissions,
+		DMPermission:             &dmPermission,
+		Middlewares:              []commands.Middleware{commands.GuildOnly(), commands.RequirePermissions(permissions)},
+		Subcommands: []*commands.Subcommand{
+			{
+				Name:        "view",
+				Description: "Show this server's settings",
+				Handler:     f.handleView,
+			},
+			{
+				Name:        "set",
+				Description: "Change a setting",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "setting",
+						Description: "The setting to change",
+						Required:    true,
+						Choices:     keys,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "value",
+						Description: "The new value",
+						Required:    true,
+					},
+				},
+				Handler: f.handleSet,
+			},
+			{
+				Name:        "reset",
+				Description: "Reset a setting, or all of them, to the default",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "setting",
+						Description: "The setting to reset (default: all)",
+						Choices:     keys,
+					},
+				},
+				Handler: f.handleReset,
+			},
+		},
+	}})
+}
+
+func (f *settingsFeature) handleView(ctx *interaction.Ctx) {
+	settings, err := f.store.Get(ctx, ctx.Interaction.GuildID)
+	if err != nil {
+		f.reject(ctx, err, "loading guild settings")
+		return
+	}
+
+	builder := util.NewEmbed(util.StyleDefault, ctx.T("settings.title"), "").
+		WithColor(util.ColorBlue).
+		WithFooter(interaction.Footer)
+	for _, def := range guildsettings.Definitions {
+		value := settings.Display(def.Key)
+		switch {
+		case value != "":
+		case def.Default != "":
+			value = ctx.T("settings.default", i18n.Vars{"value": def.Default})
+		default:
+			value = ctx.T("settings.not_set")
+		}
+		builder = builder.WithField(def.Key, fmt.Sprintf("> %s\n-# %s", value, ctx.T("settings.descriptions."+def.Key)), false)
+	}
+
+	ctx.Respond(util.InteractionResponse{Embeds: []*discordgo.MessageEmbed{builder.Build()}, Ephemeral: true})
+}
+
+func (f *settingsFeature) handleSet(ctx *interaction.Ctx) {
+	key := ctx.String("setting")
+	stored, err := f.store.Set(ctx, ctx.Interaction.GuildID, key, ctx.String("value"), ctx.UserID())
+	if err != nil {
+		f.reject(ctx, err, "saving guild setting")
+		return
+	}
+
+	value := stored
+	if settings, err := f.store.Get(ctx, ctx.Interaction.GuildID); err == nil {
+		value = settings.Display(key)
+	}
+	f.confirm(ctx, ctx.T("settings.set", i18n.Vars{"key": key, "value": value}))
+}
+
+func (f *settingsFeature) handleReset(ctx *interaction.Ctx) {
+	key := ctx.String("setting")
+	if err := f.store.Reset(ctx, ctx.Interaction.GuildID, key); err != nil {
+		f.reject(ctx, err, "resetting guild settings")
+		return
+	}
+
+	if key == "" {
+		f.confirm(ctx, ctx.T("settings.reset_all"))
+		return
+	}
+	f.confirm(ctx, ctx.T("settings.reset", i18n.Vars{"key": key}))
+}
+
+func (f *settingsFeature) confirm(ctx *interaction.Ctx, message string) {
+	embed := util.NewEmbed(util.StyleSuccess, ctx.T("settings.updated"), "> "+message).
+		WithColor(util.ColorGreen).
+		WithFooter(interaction.Footer).
+		Build()
+	ctx.Respond(util.InteractionResponse{Embeds: []*discordgo.MessageEmbed{embed}, Ephemeral: true})
+}
+
+// reject replies with the error's user message. None of the subcommands
+// defer, so ctx.Fail's edit wouldn't have a response to replace.
+func (f *settingsFeature) reject(ctx *interaction.Ctx, err error, action string) {
+	errorMessage, logMessage := ctx.ErrorMessage(err, action)
+	ctx.Log.Warn(logMessage)
+	ctx.RespondError(errorMessage)
+}
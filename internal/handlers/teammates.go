@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/service"
+	"yk-dc-bot/internal/util"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/fx"
+)
+
+const teammatesListLimit = 5
+
+var teammatesModule = fx.Module("teammates", fx.Provide(fx.Private, service.NewTeammates), fx.Provide(newTeammatesFeature))
+
+type teammatesFeature struct {
+	teammates *service.Teammates
+}
+
+func newTeammatesFeature(cfg *config.Config, teammates *service.Teammates) Feature {
+	f := &teammatesFeature{teammates: teammates}
+	return newFeature(cfg, "teammates", []*commands.Command{{
+		Name:        "teammates",
+		Description: "Show who a player queues with most and how those parties do",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "username",
+				Description: "The player's Valorant username (e.g., username#tag)",
+				Required:    true,
+			},
+			regionOption(),
+		},
+		Handler: f.handle,
+	}})
+}
+
+func (f *teammatesFeature) handle(ctx *interaction.Ctx) {
+	name, tag, ok := splitRiotID(ctx.String("username"))
+	if !ok {
+		ctx.ReplyEphemeral(ctx.T("common.invalid_riot_id_example", i18n.Vars{"command": "teammates"}))
+		return
+	}
+
+	title := ctx.T("teammates.fetching", i18n.Vars{"player": name + "#" + tag})
+	if err := ctx.Defer(title); err != nil {
+		return
+	}
+
+	data, err := f.teammates.GetPlayerTeammates(ctx, name, tag, lookupRegion(ctx), ctx.Progress(title))
+	if err != nil {
+		ctx.Fail(err, "getting player teammates")
+		return
+	}
+
+	premade := data.Matches - data.SoloGames
+	builder := util.NewEmbed(util.StyleSuccess, ctx.T("teammates.title", i18n.Vars{"player": data.AccountName + "#" + data.AccountTag}),
+		"> "+ctx.T("teammates.summary", i18n.Vars{
+			"count":     data.Matches,
+			"solo":      data.SoloGames,
+			"solo_rate": formatWinRate(ctx, data.SoloWins, data.SoloGames),
+			"premade":   premade,
+		})).
+		WithColor(util.ColorTeal)
+
+	if len(data.Teammates) == 0 {
+		builder = builder.WithField(ctx.T("teammates.none"), "> "+ctx.T("teammates.none_value"), false)
+	} else {
+		builder = builder.
+			WithField(ctx.T("teammates.most_queued"), formatTeammateStats(ctx, data.Teammates), false).
+			WithField(ctx.T("teammates.parties"), formatTeammateStats(ctx, data.Stacks), false)
+	}
+
+	ctx.EditEmbeds(builder.WithFooter(interaction.Footer).Build())
+}
+
+func formatTeammateStats(ctx *interaction.Ctx, stats []service.TeammateStats) string {
+	lines := make([]string, 0, teammatesListLimit)
+	for _, entry := range stats[:min(len(stats), teammatesListLimit)] {
+		lines = append(lines, fmt.Sprintf("> %s • %s • %s", strings.Join(entry.Names, " + "),
+			ctx.T("common.games", i18n.Vars{"count": entry.Games}), formatWinRate(ctx, entry.Wins, entry.Games)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatWinRate(ctx *interaction.Ctx, wins, games int) string {
+	if games == 0 {
+		return ctx.T("common.no_games")
+	}
+	return ctx.T("common.win_rate", i18n.Vars{"rate": fmt.Sprintf("%.0f", float64(wins)/float64(games)*100)})
+}
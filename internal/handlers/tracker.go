@@ -1,108 +1,89 @@
 package handlers
 
 import (
-	"fmt"
-	"strings"
+	"time"
 
-	"yk-dc-bot/internal/apperrors"
 	"yk-dc-bot/internal/commands"
 	"yk-dc-bot/internal/config"
-	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
 	"yk-dc-bot/internal/service"
 	"yk-dc-bot/internal/util"
 
 	"github.com/bwmarrin/discordgo"
+	"go.uber.org/fx"
 )
 
-func init() {
-	commands.AddRegistration(func() {
-		commands.Register(&commands.Command{
-			Name:        "tracker",
-			Description: "Get a player's Valorant tracker stats",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "username",
-					Description: "The player's Valorant username (e.g., username#tag)",
-					Required:    true,
-				},
-			},
-			Handler: handleTrackerCommand,
-		})
-	})
+var trackerModule = fx.Module("tracker", fx.Provide(fx.Private, service.NewTracker), fx.Provide(newTrackerFeature))
+
+type trackerFeature struct {
+	tracker *service.Tracker
 }
 
-func handleTrackerCommand(s *discordgo.Session, i *discordgo.InteractionCreate, svc *service.Service, log *logger.Logger, cfg *config.Config) {
-	options := i.ApplicationCommandData().Options
-	if len(options) < 1 {
-		util.RespondToInteraction(s, i, util.InteractionResponse{
-			Content:   "please provide a valid valorant username and tag (e.g., /tracker username#tag)",
-			Ephemeral: true,
-		})
-		return
-	}
+func newTrackerFeature(cfg *config.Config, tracker *service.Tracker) Feature {
+	f := &trackerFeature{tracker: tracker}
+	return newFeature(cfg, "tracker", []*commands.Command{{
+		Name:        "tracker",
+		Description: "Get a player's Valorant tracker stats",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "username",
+				Description: "The player's Valorant username (e.g., username#tag)",
+				Required:    true,
+			},
+		},
+		Handler: f.handle,
+		// every call scrapes tracker.gg, keep it well under their rate limits
+		Cooldowns: []commands.Cooldown{
+			{Scope: commands.CooldownUser, Period: time.Minute, Burst: 2},
+			{Scope: commands.CooldownGuild, Period: time.Minute, Burst: 10},
+		},
+	}})
+}
 
-	fullUsername := options[0].StringValue()
-	parts := strings.Split(fullUsername, "#")
-	if len(parts) != 2 {
-		util.RespondToInteraction(s, i, util.InteractionResponse{
-			Content:   "invalid riot id. please use the username#tag format",
-			Ephemeral: true,
-		})
+func (f *trackerFeature) handle(ctx *interaction.Ctx) {
+	name, tag, ok := splitRiotID(ctx.String("username"))
+	if !ok {
+		ctx.ReplyEphemeral(ctx.T("common.invalid_riot_id_example", i18n.Vars{"command": "tracker"}))
 		return
 	}
 
-	name, tag := parts[0], parts[1]
-
-	err := util.DeferResponse(s, i, util.DeferResponseOptions{
-		Ephemeral:     false,
-		CustomContent: "",
-		Embeds: []*discordgo.MessageEmbed{
-			util.NewEmbed(util.StyleDefault, fmt.Sprintf("fetching tracker data for %s#%s", name, tag), "> please wait a moment").
-				WithFooter("valorant integration").
-				Build(),
-		},
-	})
-	if err != nil {
-		log.Error("Error deferring response", "error", err)
+	player := i18n.Vars{"player": name + "#" + tag}
+	title := ctx.T("tracker.fetching", player)
+	if err := ctx.Defer(title); err != nil {
 		return
 	}
 
-	tracker := util.NewProgressTracker(s, i.Interaction, fmt.Sprintf("fetching tracker data for %s#%s", name, tag), "valorant integration", util.StyleDefault)
-	tracker.Start()
-
-	playerData, err := svc.GetPlayerTrackerData(name, tag, tracker)
+	playerData, err := f.tracker.GetPlayerTrackerData(ctx, name, tag, ctx.Progress(title))
 	if err != nil {
-		errorMessage, logMessage := apperrors.HandleError(err, "getting player tracker data")
-		log.Error(logMessage)
-		util.SendErrorEmbed(s, i.Interaction, errorMessage, log, "valorant integration")
+		ctx.Fail(err, "getting player tracker data")
 		return
 	}
 
 	var embed *discordgo.MessageEmbed
 
 	if playerData.IsPrivate {
-		embed = util.NewEmbed(util.StyleWarning, fmt.Sprintf("%s#%s's Tracker Stats", name, tag), "This profile is private").
+		embed = util.NewEmbed(util.StyleWarning, ctx.T("tracker.title", player), ctx.T("tracker.private")).
 			WithColor(util.ColorGold).
 			Build()
 	} else {
-		embed = util.NewEmbed(util.StyleSuccess, fmt.Sprintf("%s#%s's Tracker Stats", name, tag), "").
+		embed = util.NewEmbed(util.StyleSuccess, ctx.T("tracker.title", player), "").
 			WithColor(util.ColorGreen).
-			WithField("Wins / Losses", fmt.Sprintf("%s / %s (%s winrate)", playerData.Wins, playerData.Losses, playerData.WinPct), true).
-			WithField("Headshot %", playerData.HsPct, true).
-			WithField("K/D Ratio", playerData.KdRatio, true).
-			WithField("Damage Per Round", playerData.DamagePerRound, true).
-			WithField("Time Played", playerData.TimePlayed, true).
-			WithField("Rank", playerData.Rank, true).
+			WithField(ctx.T("tracker.wins_losses"), ctx.T("tracker.wins_losses_value", i18n.Vars{
+				"wins":   playerData.Wins,
+				"losses": playerData.Losses,
+				"rate":   playerData.WinPct,
+			}), true).
+			WithField(ctx.T("tracker.headshots"), playerData.HsPct, true).
+			WithField(ctx.T("tracker.kd_ratio"), playerData.KdRatio, true).
+			WithField(ctx.T("tracker.damage_per_round"), playerData.DamagePerRound, true).
+			WithField(ctx.T("tracker.time_played"), playerData.TimePlayed, true).
+			WithField(ctx.T("tracker.rank"), playerData.Rank, true).
 			WithThumbnail(playerData.AvatarUrl).
-			WithFooter("valorant integration").
+			WithFooter(interaction.Footer).
 			Build()
 	}
 
-	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Embeds: &[]*discordgo.MessageEmbed{embed},
-	})
-	if err != nil {
-		log.Error("Error editing final interaction response", "error", apperrors.Wrap(err, "INTERACTION_EDIT_ERROR", "failed to edit interaction response"))
-	}
+	ctx.EditEmbeds(embed)
 }
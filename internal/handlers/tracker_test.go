@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"testing"
+
+	"yk-dc-bot/internal/service"
+	"yk-dc-bot/internal/testkit"
+)
+
+func newTrackerBot(t *testing.T) *replayBot {
+	bot := newReplayBot(t)
+	bot.install(newTrackerFeature(bot.Config, service.NewTracker(bot.Tracker)))
+	return bot
+}
+
+func TestTracker(t *testing.T) {
+	bot := newTrackerBot(t)
+
+	i := testkit.Command("tracker").String("username", "tester#fake").Build()
+	bot.Run(t, i)
+
+	embed := bot.Discord.FinalEmbed(t, i)
+	testkit.AssertTitle(t, embed, "tester#fake's Tracker Stats")
+	testkit.AssertField(t, embed, "Wins / Losses", "64 / 51 (55.7% winrate)")
+	testkit.AssertField(t, embed, "Headshot %", "24.1%")
+	testkit.AssertField(t, embed, "K/D Ratio", "1.18")
+	testkit.AssertField(t, embed, "Damage Per Round", "148.2")
+	testkit.AssertField(t, embed, "Time Played", "61h 12m")
+	testkit.AssertField(t, embed, "Rank", "Diamond 1")
+
+	if _, ok := bot.Redis.Get("tracker:tester:fake"); !ok {
+		t.Error("profile wasn't cached")
+	}
+}
+
+func TestTrackerPrivateProfile(t *testing.T) {
+	bot := newTrackerBot(t)
+
+	i := testkit.Command("tracker").String("username", "private#fake").Build()
+	bot.Run(t, i)
+
+	embed := bot.Discord.FinalEmbed(t, i)
+	testkit.AssertTitle(t, embed, "private#fake's Tracker Stats")
+	if embed.Description != "This profile is private" {
+		t.Errorf("description = %q, want the private notice", embed.Description)
+	}
+}
+
+func TestTrackerUnknownPlayer(t *testing.T) {
+	bot := newTrackerBot(t)
+
+	i := testkit.Command("tracker").String("username", "nobody#fake").Build()
+	bot.Run(t, i)
+
+	bot.Discord.AssertError(t, i, "tracker.gg doesn't know nobody#fake yet.")
+}
+
+func TestTrackerServedFromCache(t *testing.T) {
+	bot := newTrackerBot(t)
+	bot.Redis.Set("tracker:cached:fake", `{"user":"cached#fake","wins":"1","losses":"2","winPct":"33.3%","rank":"Iron 1"}`)
+
+	// there is no fixture for this player, so only the cache can answer
+	i := testkit.Command("tracker").String("username", "cached#fake").Build()
+	bot.Run(t, i)
+
+	testkit.AssertField(t, bot.Discord.FinalEmbed(t, i), "Rank", "Iron 1")
+}
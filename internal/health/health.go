@@ -0,0 +1,182 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"yk-dc-bot/internal/bot"
+	"yk-dc-bot/internal/circuit"
+	"yk-dc-bot/internal/database"
+	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/redisclient"
+)
+
+const (
+	// checkTimeout bounds each dependency check so a hung dependency can't
+	// stall the probe past the orchestrator's own timeout.
+	checkTimeout = 2 * time.Second
+	// staleHeartbeat is how old the last gateway heartbeat ack may be before
+	// the session counts as down; Discord asks for one every ~41s.
+	staleHeartbeat = 2 * time.Minute
+)
+
+// Check is the result for one dependency.
+type Check struct {
+	Status   string         `json:"status"`
+	Error    string         `json:"error,omitempty"`
+	Duration string         `json:"duration,omitempty"`
+	Details  map[string]any `json:"details,omitempty"`
+}
+
+type Report struct {
+	Status string           `json:"status"`
+	Checks map[string]Check `json:"checks"`
+}
+
+// Checker answers /healthz and /readyz. Liveness only says the process is
+// serving HTTP; readiness checks Postgres, Redis, the Discord gateway and the
+// upstream circuit breakers, and turns unready while any of them fails.
+type Checker struct {
+	db       *database.Database
+	redis    *redisclient.Client
+	bot      *bot.DiscordBot
+	breakers *circuit.Breakers
+	log      *logger.Logger
+
+	mu        sync.Mutex
+	lastReady bool
+}
+
+func NewChecker(db *database.Database, redis *redisclient.Client, bot *bot.DiscordBot, breakers *circuit.Breakers, log *logger.Logger) *Checker {
+	return &Checker{db: db, redis: redis, bot: bot, breakers: breakers, log: log, lastReady: true}
+}
+
+func (c *Checker) Live(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (c *Checker) Ready(w http.ResponseWriter, r *http.Request) {
+	report := c.Check(r.Context())
+	status := http.StatusOK
+	if report.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+// Check runs every readiness check concurrently.
+func (c *Checker) Check(ctx context.Context) Report {
+	checks := map[string]func(ctx context.Context) Check{
+		"postgres": func(ctx context.Context) Check {
+			return ping(ctx, c.db.PingContext)
+		},
+		"redis": func(ctx context.Context) Check {
+			return ping(ctx, c.redis.Ping)
+		},
+		"gateway":   c.gateway,
+		"providers": c.providers,
+	}
+
+	report := Report{Status: "ok", Checks: make(map[string]Check, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) Check) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+			result := check(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != "ok" {
+				report.Status = "unavailable"
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	c.logTransition(report)
+	return report
+}
+
+// logTransition logs when readiness flips, rather than on every probe.
+func (c *Checker) logTransition(report Report) {
+	ready := report.Status == "ok"
+	c.mu.Lock()
+	changed := ready != c.lastReady
+	c.lastReady = ready
+	c.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	if ready {
+		c.log.Info("Bot is ready again")
+		return
+	}
+	var failing []any
+	for name, check := range report.Checks {
+		if check.Status != "ok" {
+			failing = append(failing, name, check.Error)
+		}
+	}
+	c.log.Warn("Bot is not ready", failing...)
+}
+
+func ping(ctx context.Context, fn func(context.Context) error) Check {
+	start := time.Now()
+	err := fn(ctx)
+	check := Check{Status: "ok", Duration: time.Since(start).String()}
+	if err != nil {
+		check.Status = "fail"
+		check.Error = err.Error()
+	}
+	return check
+}
+
+func (c *Checker) gateway(ctx context.Context) Check {
+	session := c.bot.Session
+	session.RLock()
+	ready := session.DataReady
+	lastAck := session.LastHeartbeatAck
+	session.RUnlock()
+
+	check := Check{Status: "ok", Details: map[string]any{"latency": session.HeartbeatLatency().String()}}
+	switch {
+	case !ready:
+		check.Status = "fail"
+		check.Error = "gateway session is not connected"
+	case time.Since(lastAck) > staleHeartbeat:
+		check.Status = "fail"
+		check.Error = "no gateway heartbeat ack since " + lastAck.Format(time.RFC3339)
+	}
+	return check
+}
+
+func (c *Checker) providers(ctx context.Context) Check {
+	check := Check{Status: "ok", Details: make(map[string]any)}
+	for _, status := range c.breakers.Statuses() {
+		check.Details[status.Name] = status
+		if status.State == circuit.Open.String() {
+			check.Status = "fail"
+			if check.Error != "" {
+				check.Error += ", "
+			}
+			check.Error += status.Name + " circuit is open"
+		}
+	}
+	return check
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
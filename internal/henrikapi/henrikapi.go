@@ -10,46 +10,83 @@ import (
 	"strings"
 	"time"
 	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/circuit"
 	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/fixtures"
 	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/metrics"
 	redisclient "yk-dc-bot/internal/redisclient"
+	"yk-dc-bot/internal/schema"
+	"yk-dc-bot/internal/tracing"
 )
 
-const baseURL = "https://api.henrikdev.xyz/valorant"
-
 type HenrikDevAPI struct {
+	cfg         *config.Config
 	apiKey      string
+	baseURL     string
 	redisClient *redisclient.Client
 	log         *logger.Logger
 	httpClient  *http.Client
+	schemas     *schema.Detector
+	breaker     *circuit.Breaker
+	metrics     *metrics.Bot
 }
 
-func NewHenrikDevAPI(cfg *config.Config, redisClient *redisclient.Client, log *logger.Logger) *HenrikDevAPI {
+func NewHenrikDevAPI(cfg *config.Config, redisClient *redisclient.Client, log *logger.Logger, store *fixtures.Store, schemas *schema.Detector, breakers *circuit.Breakers, m *metrics.Bot) *HenrikDevAPI {
 	return &HenrikDevAPI{
+		cfg:         cfg,
 		apiKey:      cfg.HdevApiKey,
+		baseURL:     cfg.APIs.HenrikBaseURL,
 		redisClient: redisClient,
 		log:         log,
 		httpClient: &http.Client{
-			Timeout: time.Second * 10,
+			Timeout:   time.Second * 10,
+			Transport: store.Transport(http.DefaultTransport),
 		},
+		schemas: schemas,
+		breaker: breakers.Get("henrikdev"),
+		metrics: m,
 	}
 }
 
-func (c *HenrikDevAPI) makeRequest(endpoint string) ([]byte, error) {
-	req, err := http.NewRequest("GET", baseURL+endpoint, nil)
+// makeRequest fetches endpoint; route names it in metrics, since the path
+// itself contains player IDs.
+func (c *HenrikDevAPI) makeRequest(ctx context.Context, route, endpoint string) (body []byte, err error) {
+	ctx, span := tracing.StartClient(ctx, "GET henrikdev "+route,
+		tracing.Attr("http.request.method", "GET"),
+		tracing.Attr("http.route", route),
+		tracing.Attr("url.full", c.baseURL+endpoint),
+	)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	req.Header.Set("Authorization", c.apiKey)
 
+	if err := c.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		c.breaker.RecordErr(ctx)
+		c.metrics.ObserveUpstream("henrikdev", route, 0, start)
 		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
+	c.breaker.Record(resp.StatusCode < http.StatusInternalServerError)
+	c.metrics.ObserveUpstream("henrikdev", route, resp.StatusCode, start)
+	span.SetAttributes(tracing.Attr("http.response.status_code", resp.StatusCode))
+	logger.FromContext(ctx, c.log).Debug("HenrikDev request", "endpoint", endpoint, "status", resp.StatusCode, "duration", time.Since(start))
 
-	body, err := io.ReadAll(resp.Body)
+	body, err = io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, apperrors.Wrap(err, "API_REQUEST_ERROR", "error making request")
 	}
@@ -135,8 +172,7 @@ func (c *Card) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func (c *HenrikDevAPI) GetAccountByNameTag(name, tag string) (*AccountData, error) {
-	ctx := context.Background()
+func (c *HenrikDevAPI) GetAccountByNameTag(ctx context.Context, name, tag string) (*AccountData, error) {
 	cacheKey := fmt.Sprintf("account:%s:%s", name, tag)
 
 	cachedData, err := c.redisClient.Get(ctx, cacheKey)
@@ -148,7 +184,7 @@ func (c *HenrikDevAPI) GetAccountByNameTag(name, tag string) (*AccountData, erro
 	}
 
 	endpoint := fmt.Sprintf("/v2/account/%s/%s", name, tag)
-	body, err := c.makeRequest(endpoint)
+	body, err := c.makeRequest(ctx, "account", endpoint)
 	if err != nil {
 		if strings.Contains(err.Error(), "404") {
 			return nil, apperrors.Wrap(err, "ACCOUNT_FETCH_ERROR", "Account not found")
@@ -157,6 +193,10 @@ func (c *HenrikDevAPI) GetAccountByNameTag(name, tag string) (*AccountData, erro
 		return nil, err
 	}
 
+	if err := c.schemas.Validate(AccountSchema, body); err != nil {
+		return nil, err
+	}
+
 	var response struct {
 		Status int         `json:"status"`
 		Data   AccountData `json:"data"`
@@ -174,13 +214,12 @@ func (c *HenrikDevAPI) GetAccountByNameTag(name, tag string) (*AccountData, erro
 	}
 
 	cacheData, _ := json.Marshal(response.Data)
-	c.redisClient.Set(ctx, cacheKey, string(cacheData), 12*time.Hour)
+	c.redisClient.Set(ctx, cacheKey, string(cacheData), c.cfg.Runtime().CacheTTLs.Account)
 
 	return &response.Data, nil
 }
 
-func (c *HenrikDevAPI) GetMMRByPUUID(region, puuid string) (*MMRData, error) {
-	ctx := context.Background()
+func (c *HenrikDevAPI) GetMMRByPUUID(ctx context.Context, region, puuid string) (*MMRData, error) {
 	cacheKey := fmt.Sprintf("mmr:%s:%s", region, puuid)
 
 	cachedData, err := c.redisClient.Get(ctx, cacheKey)
@@ -192,11 +231,15 @@ func (c *HenrikDevAPI) GetMMRByPUUID(region, puuid string) (*MMRData, error) {
 	}
 
 	endpoint := fmt.Sprintf("/v2/by-puuid/mmr/%s/%s", region, puuid)
-	body, err := c.makeRequest(endpoint)
+	body, err := c.makeRequest(ctx, "mmr", endpoint)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := c.schemas.Validate(MMRSchema, body); err != nil {
+		return nil, err
+	}
+
 	var response struct {
 		Status int     `json:"status"`
 		Data   MMRData `json:"data"`
@@ -210,15 +253,14 @@ func (c *HenrikDevAPI) GetMMRByPUUID(region, puuid string) (*MMRData, error) {
 	}
 
 	cacheData, _ := json.Marshal(response.Data)
-	if err := c.redisClient.Set(ctx, cacheKey, string(cacheData), 1*time.Minute); err != nil {
+	if err := c.redisClient.Set(ctx, cacheKey, string(cacheData), c.cfg.Runtime().CacheTTLs.MMR); err != nil {
 		return nil, err
 	}
 
 	return &response.Data, nil
 }
 
-func (c *HenrikDevAPI) GetDetailedAccountByPUUID(puuid string) (*DetailedAccountData, error) {
-	ctx := context.Background()
+func (c *HenrikDevAPI) GetDetailedAccountByPUUID(ctx context.Context, puuid string) (*DetailedAccountData, error) {
 	cacheKey := fmt.Sprintf("detailed_account:%s", puuid)
 
 	cachedData, err := c.redisClient.Get(ctx, cacheKey)
@@ -230,11 +272,15 @@ func (c *HenrikDevAPI) GetDetailedAccountByPUUID(puuid string) (*DetailedAccount
 	}
 
 	endpoint := fmt.Sprintf("/v2/by-puuid/account/%s", puuid)
-	body, err := c.makeRequest(endpoint)
+	body, err := c.makeRequest(ctx, "account_by_puuid", endpoint)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := c.schemas.Validate(DetailedAccountSchema, body); err != nil {
+		return nil, err
+	}
+
 	var response struct {
 		Status int                 `json:"status"`
 		Data   DetailedAccountData `json:"data"`
@@ -248,7 +294,7 @@ func (c *HenrikDevAPI) GetDetailedAccountByPUUID(puuid string) (*DetailedAccount
 	}
 
 	cacheData, _ := json.Marshal(response.Data)
-	if err := c.redisClient.Set(ctx, cacheKey, string(cacheData), 4*time.Hour); err != nil {
+	if err := c.redisClient.Set(ctx, cacheKey, string(cacheData), c.cfg.Runtime().CacheTTLs.DetailedAccount); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,255 @@
+package henrikapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/logger"
+)
+
+type Location struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type MatchMetadata struct {
+	Map          string `json:"map"`
+	GameVersion  string `json:"game_version"`
+	GameLength   int64  `json:"game_length"`
+	GameStart    int64  `json:"game_start"`
+	RoundsPlayed int    `json:"rounds_played"`
+	Mode         string `json:"mode"`
+	ModeID       string `json:"mode_id"`
+	Queue        string `json:"queue"`
+	SeasonID     string `json:"season_id"`
+	MatchID      string `json:"matchid"`
+	Region       string `json:"region"`
+	Cluster      string `json:"cluster"`
+}
+
+type MatchPlayerStats struct {
+	Score     int `json:"score"`
+	Kills     int `json:"kills"`
+	Deaths    int `json:"deaths"`
+	Assists   int `json:"assists"`
+	Bodyshots int `json:"bodyshots"`
+	Headshots int `json:"headshots"`
+	Legshots  int `json:"legshots"`
+}
+
+type MatchPlayer struct {
+	Puuid              string           `json:"puuid"`
+	Name               string           `json:"name"`
+	Tag                string           `json:"tag"`
+	Team               string           `json:"team"`
+	Level              int              `json:"level"`
+	Character          string           `json:"character"`
+	CurrentTier        int              `json:"currenttier"`
+	CurrentTierPatched string           `json:"currenttier_patched"`
+	PartyID            string           `json:"party_id"`
+	Stats              MatchPlayerStats `json:"stats"`
+	DamageMade         int              `json:"damage_made"`
+	DamageReceived     int              `json:"damage_received"`
+}
+
+type MatchTeam struct {
+	HasWon     bool `json:"has_won"`
+	RoundsWon  int  `json:"rounds_won"`
+	RoundsLost int  `json:"rounds_lost"`
+}
+
+type RoundEconomy struct {
+	LoadoutValue int `json:"loadout_value"`
+	Remaining    int `json:"remaining"`
+	Spent        int `json:"spent"`
+	Weapon       struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"weapon"`
+	Armor struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"armor"`
+}
+
+type RoundPlayerStats struct {
+	PlayerPuuid       string       `json:"player_puuid"`
+	PlayerDisplayName string       `json:"player_display_name"`
+	PlayerTeam        string       `json:"player_team"`
+	Damage            int          `json:"damage"`
+	Kills             int          `json:"kills"`
+	Score             int          `json:"score"`
+	Economy           RoundEconomy `json:"economy"`
+	WasAfk            bool         `json:"was_afk"`
+}
+
+type MatchRound struct {
+	WinningTeam string             `json:"winning_team"`
+	EndType     string             `json:"end_type"`
+	BombPlanted bool               `json:"bomb_planted"`
+	BombDefused bool               `json:"bomb_defused"`
+	PlayerStats []RoundPlayerStats `json:"player_stats"`
+}
+
+type PlayerLocation struct {
+	PlayerPuuid       string   `json:"player_puuid"`
+	PlayerDisplayName string   `json:"player_display_name"`
+	PlayerTeam        string   `json:"player_team"`
+	Location          Location `json:"location"`
+	ViewRadians       float64  `json:"view_radians"`
+}
+
+type KillAssistant struct {
+	AssistantPuuid       string `json:"assistant_puuid"`
+	AssistantDisplayName string `json:"assistant_display_name"`
+	AssistantTeam        string `json:"assistant_team"`
+}
+
+type MatchKill struct {
+	KillTimeInRound       int64            `json:"kill_time_in_round"`
+	KillTimeInMatch       int64            `json:"kill_time_in_match"`
+	Round                 int              `json:"round"`
+	KillerPuuid           string           `json:"killer_puuid"`
+	KillerDisplayName     string           `json:"killer_display_name"`
+	KillerTeam            string           `json:"killer_team"`
+	VictimPuuid           string           `json:"victim_puuid"`
+	VictimDisplayName     string           `json:"victim_display_name"`
+	VictimTeam            string           `json:"victim_team"`
+	VictimDeathLocation   Location         `json:"victim_death_location"`
+	DamageWeaponName      string           `json:"damage_weapon_name"`
+	PlayerLocationsOnKill []PlayerLocation `json:"player_locations_on_kill"`
+	Assistants            []KillAssistant  `json:"assistants"`
+}
+
+type MatchData struct {
+	Metadata MatchMetadata `json:"metadata"`
+	Players  struct {
+		AllPlayers []MatchPlayer `json:"all_players"`
+	} `json:"players"`
+	Teams struct {
+		Red  MatchTeam `json:"red"`
+		Blue MatchTeam `json:"blue"`
+	} `json:"teams"`
+	Rounds []MatchRound `json:"rounds"`
+	Kills  []MatchKill  `json:"kills"`
+}
+
+func (m *MatchData) Player(puuid string) (*MatchPlayer, bool) {
+	for idx := range m.Players.AllPlayers {
+		if m.Players.AllPlayers[idx].Puuid == puuid {
+			return &m.Players.AllPlayers[idx], true
+		}
+	}
+	return nil, false
+}
+
+func (m *MatchData) PartyMembers(puuid string) []MatchPlayer {
+	player, ok := m.Player(puuid)
+	if !ok || player.PartyID == "" {
+		return nil
+	}
+
+	var members []MatchPlayer
+	for _, p := range m.Players.AllPlayers {
+		if p.Puuid != puuid && p.PartyID == player.PartyID {
+			members = append(members, p)
+		}
+	}
+	return members
+}
+
+func (m *MatchData) Team(name string) MatchTeam {
+	if strings.EqualFold(name, "red") {
+		return m.Teams.Red
+	}
+	return m.Teams.Blue
+}
+
+func (c *HenrikDevAPI) GetMatchesByPUUID(ctx context.Context, region, puuid string, size int) ([]MatchData, error) {
+	cacheKey := fmt.Sprintf("matches:%s:%s:%d", region, puuid, size)
+
+	cachedData, err := c.redisClient.Get(ctx, cacheKey)
+	if err == nil {
+		var matches []MatchData
+		if err := json.Unmarshal([]byte(cachedData), &matches); err == nil {
+			return matches, nil
+		}
+	}
+
+	endpoint := fmt.Sprintf("/v3/by-puuid/matches/%s/%s?mode=competitive&size=%d", region, puuid, size)
+	body, err := c.makeRequest(ctx, "matches", endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.schemas.Validate(MatchesSchema, body); err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Status int         `json:"status"`
+		Data   []MatchData `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, apperrors.Wrap(err, "MATCHES_FETCH_ERROR", "Failed to fetch match history")
+	}
+
+	if response.Status != 200 {
+		return nil, apperrors.New("MATCHES_FETCH_ERROR", fmt.Sprintf("Failed to fetch match history, status code: %d", response.Status))
+	}
+
+	cacheData, _ := json.Marshal(response.Data)
+	if err := c.redisClient.Set(ctx, cacheKey, string(cacheData), c.cfg.Runtime().CacheTTLs.Matches); err != nil {
+		logger.FromContext(ctx, c.log).Error("Failed to cache match history", "error", err)
+	}
+
+	return response.Data, nil
+}
+
+func (c *HenrikDevAPI) GetMatchByID(ctx context.Context, matchID string) (*MatchData, error) {
+	cacheKey := fmt.Sprintf("match:%s", matchID)
+
+	cachedData, err := c.redisClient.Get(ctx, cacheKey)
+	if err == nil {
+		var match MatchData
+		if err := json.Unmarshal([]byte(cachedData), &match); err == nil {
+			return &match, nil
+		}
+	}
+
+	endpoint := fmt.Sprintf("/v2/match/%s", matchID)
+	body, err := c.makeRequest(ctx, "match", endpoint)
+	if err != nil {
+		if strings.Contains(err.Error(), "404") {
+			return nil, apperrors.Wrap(err, "MATCH_FETCH_ERROR", "Match not found", "Couldn't find a match with that ID")
+		}
+
+		return nil, err
+	}
+
+	if err := c.schemas.Validate(MatchSchema, body); err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Status int       `json:"status"`
+		Data   MatchData `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, apperrors.Wrap(err, "MATCH_FETCH_ERROR", "Failed to fetch match details")
+	}
+
+	if response.Status != 200 {
+		return nil, apperrors.New("MATCH_FETCH_ERROR", fmt.Sprintf("Failed to fetch match details, status code: %d", response.Status))
+	}
+
+	cacheData, _ := json.Marshal(response.Data)
+	if err := c.redisClient.Set(ctx, cacheKey, string(cacheData), c.cfg.Runtime().CacheTTLs.Match); err != nil {
+		logger.FromContext(ctx, c.log).Error("Failed to cache match details", "error", err)
+	}
+
+	return &response.Data, nil
+}
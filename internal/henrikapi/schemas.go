@@ -0,0 +1,74 @@
+package henrikapi
+
+import "yk-dc-bot/internal/schema"
+
+// Schemas for the HenrikDev responses, covering the fields the bot reads.
+// Keep these in sync with the structs when a new field is used.
+var (
+	AccountSchema = schema.New("henrikdev.account",
+		schema.Required("status", schema.Number),
+		schema.Required("data", schema.Object),
+		schema.Required("data.puuid", schema.String),
+		schema.Required("data.region", schema.String),
+		schema.Required("data.name", schema.String),
+		schema.Required("data.tag", schema.String),
+		schema.Optional("data.account_level", schema.Number),
+		schema.Optional("data.card", schema.String),
+	)
+
+	DetailedAccountSchema = schema.New("henrikdev.detailed_account",
+		schema.Required("status", schema.Number),
+		schema.Required("data", schema.Object),
+		schema.Required("data.puuid", schema.String),
+		schema.Required("data.name", schema.String),
+		schema.Required("data.tag", schema.String),
+		schema.Optional("data.card", schema.Any),
+		schema.Optional("data.last_update_raw", schema.Number),
+	)
+
+	MMRSchema = schema.New("henrikdev.mmr",
+		schema.Required("status", schema.Number),
+		schema.Required("data.current_data", schema.Object),
+		schema.Required("data.current_data.currenttier", schema.Number),
+		schema.Required("data.current_data.currenttierpatched", schema.String),
+		schema.Optional("data.current_data.ranking_in_tier", schema.Number),
+		schema.Optional("data.current_data.elo", schema.Number),
+		schema.Optional("data.current_data.images", schema.Object),
+	)
+
+	MatchesSchema = schema.New("henrikdev.matches", append([]schema.Field{
+		schema.Required("status", schema.Number),
+		schema.Required("data", schema.Array),
+	}, matchFields("data.0")...)...)
+
+	MatchSchema = schema.New("henrikdev.match", append([]schema.Field{
+		schema.Required("status", schema.Number),
+		schema.Required("data", schema.Object),
+	}, matchFields("data")...)...)
+)
+
+// matchFields describes a match object at prefix, sampling the first element
+// of each list.
+func matchFields(prefix string) []schema.Field {
+	return []schema.Field{
+		schema.Required(prefix+".metadata.matchid", schema.String),
+		schema.Required(prefix+".metadata.map", schema.String),
+		schema.Required(prefix+".metadata.game_start", schema.Number),
+		schema.Required(prefix+".metadata.rounds_played", schema.Number),
+		schema.Required(prefix+".players.all_players", schema.Array),
+		schema.Required(prefix+".players.all_players.0.puuid", schema.String),
+		schema.Required(prefix+".players.all_players.0.team", schema.String),
+		schema.Required(prefix+".players.all_players.0.character", schema.String),
+		schema.Required(prefix+".players.all_players.0.stats.kills", schema.Number),
+		schema.Required(prefix+".players.all_players.0.stats.deaths", schema.Number),
+		schema.Optional(prefix+".players.all_players.0.party_id", schema.String),
+		schema.Optional(prefix+".players.all_players.0.damage_made", schema.Number),
+		schema.Required(prefix+".teams.red.has_won", schema.Bool),
+		schema.Required(prefix+".teams.blue.has_won", schema.Bool),
+		schema.Optional(prefix+".rounds", schema.Array),
+		schema.Optional(prefix+".rounds.0.winning_team", schema.String),
+		schema.Optional(prefix+".rounds.0.player_stats.0.economy.loadout_value", schema.Number),
+		schema.Optional(prefix+".kills", schema.Array),
+		schema.Optional(prefix+".kills.0.victim_death_location.x", schema.Number),
+	}
+}
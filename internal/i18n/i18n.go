@@ -0,0 +1,252 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Fallback is the locale every message is written in first. Messages missing
+// from another catalog are shown in it.
+const Fallback = discordgo.EnglishUS
+
+//go:embed locales/*.json
+var files embed.FS
+
+// NewCatalog loads the catalogs shipped with the bot.
+func NewCatalog() (*Catalog, error) {
+	return Load(files)
+}
+
+// Vars fill a message's {placeholders}. A "count" var also picks the plural
+// form.
+type Vars map[string]any
+
+// message holds a message's plural forms, keyed by CLDR category. Messages
+// without plurals only have "other".
+type message map[string]string
+
+// Catalog holds the messages of every supported locale, keyed by their dotted
+// path in the locale file.
+type Catalog struct {
+	messages map[discordgo.Locale]map[string]message
+	// errors maps the English user message of an error to its key, so
+	// messages coming from deeper layers can be translated as they are.
+	errors map[string]string
+}
+
+// Load reads one JSON file per locale from locales/, named after the Discord
+// locale code (e.g. locales/es-ES.json). Nested objects become dotted keys; an
+// object whose keys are all plural categories is a pluralized message.
+func Load(fsys fs.FS) (*Catalog, error) {
+	paths, err := fs.Glob(fsys, "locales/*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Catalog{
+		messages: make(map[discordgo.Locale]map[string]message),
+		errors:   make(map[string]string),
+	}
+	for _, p := range paths {
+		locale := discordgo.Locale(strings.TrimSuffix(path.Base(p), ".json"))
+		if _, ok := discordgo.Locales[locale]; !ok {
+			return nil, fmt.Errorf("%s: %s is not a Discord locale", p, locale)
+		}
+
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+		var tree map[string]any
+		if err := json.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+
+		messages := make(map[string]message)
+		if err := flatten("", tree, messages); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		c.messages[locale] = messages
+	}
+
+	if _, ok := c.messages[Fallback]; !ok {
+		return nil, fmt.Errorf("missing catalog for %s", Fallback)
+	}
+	for key, msg := range c.messages[Fallback] {
+		if strings.HasPrefix(key, "errors.") {
+			c.errors[msg["other"]] = key
+		}
+	}
+	return c, nil
+}
+
+var pluralCategories = []string{"zero", "one", "two", "few", "many", "other"}
+
+func flatten(prefix string, tree map[string]any, out map[string]message) error {
+	for name, value := range tree {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		switch value := value.(type) {
+		case string:
+			out[key] = message{"other": value}
+		case map[string]any:
+			if forms, ok := pluralForms(value); ok {
+				out[key] = forms
+				continue
+			}
+			if err := flatten(key, value, out); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s: expected a string or an object, got %T", key, value)
+		}
+	}
+	return nil
+}
+
+func pluralForms(value map[string]any) (message, bool) {
+	if _, ok := value["other"].(string); !ok {
+		return nil, false
+	}
+	forms := make(message, len(value))
+	for category, form := range value {
+		text, ok := form.(string)
+		if !ok || !slices.Contains(pluralCategories, category) {
+			return nil, false
+		}
+		forms[category] = text
+	}
+	return forms, true
+}
+
+// Locales lists the supported locales, sorted.
+func (c *Catalog) Locales() []discordgo.Locale {
+	locales := make([]discordgo.Locale, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Slice(locales, func(a, b int) bool { return locales[a] < locales[b] })
+	return locales
+}
+
+// Resolve returns the first of candidates the catalog supports, matching on
+// the language alone when the exact locale is missing (es-419 gets es-ES), or
+// Fallback when none are supported.
+func (c *Catalog) Resolve(candidates ...discordgo.Locale) discordgo.Locale {
+	for _, candidate := range candidates {
+		if _, ok := c.messages[candidate]; ok {
+			return candidate
+		}
+		lang := language(candidate)
+		if lang == "" {
+			continue
+		}
+		for _, locale := range c.Locales() {
+			if language(locale) == lang {
+				return locale
+			}
+		}
+	}
+	return Fallback
+}
+
+// T returns the message for key in locale, falling back to Fallback and then
+// to the key itself.
+func (c *Catalog) T(locale discordgo.Locale, key string, vars ...Vars) string {
+	locale = c.Resolve(locale)
+	msg, ok := c.messages[locale][key]
+	if !ok {
+		locale = Fallback
+		msg, ok = c.messages[Fallback][key]
+	}
+	if !ok {
+		return key
+	}
+
+	merged := Vars{}
+	for _, v := range vars {
+		for name, value := range v {
+			merged[name] = value
+		}
+	}
+	form := msg["other"]
+	if count, ok := number(merged["count"]); ok {
+		if text, ok := msg[pluralCategory(language(locale), count)]; ok {
+			form = text
+		}
+	}
+	return substitute(form, merged)
+}
+
+// Error translates an error's English user message. Messages that aren't in
+// the errors section, such as ones built with fmt.Sprintf, are returned as
+// they are.
+func (c *Catalog) Error(locale discordgo.Locale, userMessage string) string {
+	key, ok := c.errors[userMessage]
+	if !ok {
+		return userMessage
+	}
+	return c.T(locale, key)
+}
+
+// Localizations returns key's message in every locale but Fallback, for
+// command names and descriptions. Locales without the message are left out.
+func (c *Catalog) Localizations(key string) map[discordgo.Locale]string {
+	localizations := make(map[discordgo.Locale]string)
+	for locale, messages := range c.messages {
+		if locale == Fallback {
+			continue
+		}
+		if msg, ok := messages[key]; ok {
+			localizations[locale] = msg["other"]
+		}
+	}
+	if len(localizations) == 0 {
+		return nil
+	}
+	return localizations
+}
+
+func substitute(text string, vars Vars) string {
+	if len(vars) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	pairs := make([]string, 0, len(vars)*2)
+	for name, value := range vars {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+func language(locale discordgo.Locale) string {
+	lang, _, _ := strings.Cut(string(locale), "-")
+	return lang
+}
+
+func number(value any) (int64, bool) {
+	switch n := value.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case int32:
+		return int64(n), true
+	case uint:
+		return int64(n), true
+	case float64:
+		return int64(n), n == float64(int64(n))
+	default:
+		return 0, false
+	}
+}
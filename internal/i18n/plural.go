@@ -0,0 +1,71 @@
+package i18n
+
+// pluralCategory picks the CLDR plural category for a whole number n. Only
+// the rules of languages Discord supports are covered; anything else uses the
+// English rule.
+func pluralCategory(lang string, n int64) string {
+	if n < 0 {
+		n = -n
+	}
+	mod10, mod100 := n%10, n%100
+
+	switch lang {
+	case "ja", "ko", "zh", "th", "vi", "id":
+		return "other"
+	case "fr", "pt", "hi":
+		if n == 0 || n == 1 {
+			return "one"
+		}
+		return "other"
+	case "ru", "uk", "hr":
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "pl":
+		switch {
+		case n == 1:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "cs":
+		switch {
+		case n == 1:
+			return "one"
+		case n >= 2 && n <= 4:
+			return "few"
+		default:
+			return "other"
+		}
+	case "lt":
+		switch {
+		case mod10 == 1 && (mod100 < 11 || mod100 > 19):
+			return "one"
+		case mod10 >= 2 && (mod100 < 11 || mod100 > 19):
+			return "few"
+		default:
+			return "other"
+		}
+	case "ro":
+		switch {
+		case n == 1:
+			return "one"
+		case n == 0 || (mod100 >= 2 && mod100 <= 19):
+			return "few"
+		default:
+			return "other"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
@@ -0,0 +1,511 @@
+package interaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/errorreport"
+	"yk-dc-bot/internal/guildsettings"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/metrics"
+	"yk-dc-bot/internal/tracing"
+	"yk-dc-bot/internal/util"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// TokenLifetime is how long Discord accepts edits and followups for an
+// interaction after it was created.
+const TokenLifetime = 15 * time.Minute
+
+// Footer is the embed footer used across the bot's responses.
+const Footer = "valorant integration"
+
+type Option = discordgo.ApplicationCommandInteractionDataOption
+
+// Ctx is everything a command, component or modal handler needs for a single
+// interaction. It embeds a context.Context that expires with the interaction
+// token, so it can be passed straight to Redis, SQL or HTTP calls.
+type Ctx struct {
+	context.Context
+
+	Session     *discordgo.Session
+	Interaction *discordgo.InteractionCreate
+	Config      *config.Config
+	// Catalog translates the replies.
+	Catalog *i18n.Catalog
+	// Guild holds the guild's settings. It starts out as the defaults and is
+	// loaded by the commands.GuildSettings middleware, or by the component
+	// router for buttons and modals.
+	Guild *guildsettings.Settings
+	// CorrelationID ties the log lines of this interaction together and is
+	// shown on error embeds so users can quote it in bug reports. It is the
+	// start of the interaction's trace ID, which the logs carry in full.
+	CorrelationID string
+	// Log is tagged with the guild, user, command and correlation id of the
+	// interaction. The embedded context carries it too, for logger.FromContext.
+	Log *logger.Logger
+	// Errors receives the failures passed to Fail. It may be nil.
+	Errors *errorreport.Reporter
+	// Metrics counts the progress trackers' edits. It may be nil.
+	Metrics *metrics.Bot
+
+	options map[string]*Option
+	cancel  context.CancelFunc
+	failed  bool
+	span    *tracing.Span
+
+	mu       sync.Mutex
+	trackers []*util.ProgressTracker
+	state    atomic.Int32
+	// sendMu is held across every response sent for the interaction, and by
+	// Interrupt, so a send that started before the interruption finishes
+	// before the restart notice and none starts after it.
+	sendMu sync.Mutex
+}
+
+const (
+	stateRunning int32 = iota
+	stateClosed
+	stateInterrupted
+)
+
+func New(s *discordgo.Session, i *discordgo.InteractionCreate, catalog *i18n.Catalog, log *logger.Logger, cfg *config.Config) *Ctx {
+	created, err := discordgo.SnowflakeTimestamp(i.ID)
+	if err != nil {
+		created = time.Now()
+	}
+	base, cancel := context.WithDeadline(context.Background(), created.Add(TokenLifetime))
+
+	ctx := &Ctx{
+		Session:     s,
+		Interaction: i,
+		Config:      cfg,
+		Catalog:     catalog,
+		Guild:       guildsettings.Defaults(i.GuildID),
+		options:     make(map[string]*Option),
+		cancel:      cancel,
+	}
+
+	if i.Type == discordgo.InteractionApplicationCommand || i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+		_, options := Walk(i.ApplicationCommandData())
+		for _, option := range options {
+			ctx.options[option.Name] = option
+		}
+	}
+
+	base, ctx.span = tracing.StartServer(base, "interaction "+ctx.Command(),
+		tracing.Attr("discord.interaction.type", i.Type.String()),
+		tracing.Attr("discord.guild_id", i.GuildID),
+		tracing.Attr("discord.user_id", ctx.UserID()),
+	)
+	traceID := ctx.span.TraceID.String()
+	ctx.CorrelationID = traceID[:12]
+	ctx.span.SetAttributes(tracing.Attr("correlation_id", ctx.CorrelationID))
+
+	ctx.Log = log.With("guild", i.GuildID, "user", ctx.UserID(), "command", ctx.Command(), "correlation_id", ctx.CorrelationID, "trace_id", traceID)
+	ctx.Context = logger.NewContext(base, ctx.Log)
+	return ctx
+}
+
+// Close waits for the handler's progress trackers to make their last edit,
+// then ends the interaction's span and releases the context. Call it once the
+// handler has returned.
+func (c *Ctx) Close() {
+	c.state.CompareAndSwap(stateRunning, stateClosed)
+	c.stopTrackers()
+
+	c.span.SetAttributes(tracing.Attr("failed", c.failed), tracing.Attr("interrupted", c.Interrupted()))
+	c.span.End()
+	c.cancel()
+}
+
+// Interrupt tells the user the bot is restarting and cancels the context.
+// Anything the handler sends afterwards is dropped, so the notice stays. It
+// is used on shutdown for interactions that didn't finish in time, and for
+// ones that arrive while the bot is draining. Once Close has been called it
+// does nothing, so a response that just finished isn't overwritten.
+func (c *Ctx) Interrupt() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if !c.state.CompareAndSwap(stateRunning, stateInterrupted) {
+		return
+	}
+	c.mu.Lock()
+	for _, tracker := range c.trackers {
+		tracker.Silence()
+	}
+	c.mu.Unlock()
+	c.cancel()
+
+	embed := util.NewEmbed(util.StyleWarning, c.T("common.restarting_title"), "> "+c.T("common.restarting")).
+		WithFooter(Footer).
+		Build()
+	err := util.RespondToInteraction(c.Session, c.Interaction, util.InteractionResponse{
+		Embeds:    []*discordgo.MessageEmbed{embed},
+		Ephemeral: true,
+	})
+	var restErr *discordgo.RESTError
+	if err != nil && errors.As(err, &restErr) {
+		_, err = c.Session.InteractionResponseEdit(c.Interaction.Interaction, &discordgo.WebhookEdit{
+			Embeds:     &[]*discordgo.MessageEmbed{embed},
+			Components: &[]discordgo.MessageComponent{},
+		})
+	}
+	if err != nil {
+		c.Log.Warn("Failed to tell the user the bot is restarting", "error", err)
+	}
+}
+
+// Interrupted reports whether Interrupt was called.
+func (c *Ctx) Interrupted() bool {
+	return c.state.Load() == stateInterrupted
+}
+
+// send runs fn under sendMu unless the interaction was interrupted, and
+// reports whether it ran.
+func (c *Ctx) send(fn func() error) (bool, error) {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if c.Interrupted() {
+		return false, nil
+	}
+	return true, fn()
+}
+
+// Command is the full "/group sub" path for commands and the custom ID for
+// components and modals.
+func (c *Ctx) Command() string {
+	switch c.Interaction.Type {
+	case discordgo.InteractionApplicationCommand, discordgo.InteractionApplicationCommandAutocomplete:
+		segments, _ := Walk(c.Interaction.ApplicationCommandData())
+		return strings.Join(segments, " ")
+	case discordgo.InteractionMessageComponent:
+		return c.Interaction.MessageComponentData().CustomID
+	case discordgo.InteractionModalSubmit:
+		return c.Interaction.ModalSubmitData().CustomID
+	default:
+		return ""
+	}
+}
+
+// Locale is the language replies are written in: the guild's language setting
+// when an admin picked one, otherwise the user's Discord language.
+func (c *Ctx) Locale() discordgo.Locale {
+	if language, ok := c.Guild.Values[guildsettings.KeyLanguage]; ok {
+		return c.Catalog.Resolve(discordgo.Locale(language))
+	}
+	candidates := []discordgo.Locale{c.Interaction.Locale}
+	if c.Interaction.GuildLocale != nil {
+		candidates = append(candidates, *c.Interaction.GuildLocale)
+	}
+	return c.Catalog.Resolve(candidates...)
+}
+
+// T returns the catalog message for key in the interaction's locale.
+func (c *Ctx) T(key string, vars ...i18n.Vars) string {
+	return c.Catalog.T(c.Locale(), key, vars...)
+}
+
+func (c *Ctx) UserID() string {
+	if c.Interaction.Member != nil && c.Interaction.Member.User != nil {
+		return c.Interaction.Member.User.ID
+	}
+	if c.Interaction.User != nil {
+		return c.Interaction.User.ID
+	}
+	return ""
+}
+
+// Walk follows the subcommand group and subcommand options of an invocation,
+// returning the path segments and the options passed to the leaf command.
+func Walk(data discordgo.ApplicationCommandInteractionData) ([]string, []*Option) {
+	segments := []string{data.Name}
+	options := data.Options
+	for len(options) == 1 && isSubcommandOption(options[0]) {
+		segments = append(segments, options[0].Name)
+		options = options[0].Options
+	}
+	return segments, options
+}
+
+func isSubcommandOption(option *Option) bool {
+	return option.Type == discordgo.ApplicationCommandOptionSubCommandGroup ||
+		option.Type == discordgo.ApplicationCommandOptionSubCommand
+}
+
+func (c *Ctx) Option(name string) (*Option, bool) {
+	option, ok := c.options[name]
+	return option, ok
+}
+
+func (c *Ctx) Has(name string) bool {
+	_, ok := c.options[name]
+	return ok
+}
+
+// String returns the named string option, trimmed, or "" if it wasn't given.
+func (c *Ctx) String(name string) string {
+	if option, ok := c.options[name]; ok {
+		return strings.TrimSpace(option.StringValue())
+	}
+	return ""
+}
+
+func (c *Ctx) Int(name string) int64 {
+	return c.IntOr(name, 0)
+}
+
+func (c *Ctx) IntOr(name string, fallback int64) int64 {
+	if option, ok := c.options[name]; ok {
+		return option.IntValue()
+	}
+	return fallback
+}
+
+func (c *Ctx) Float(name string) float64 {
+	if option, ok := c.options[name]; ok {
+		return option.FloatValue()
+	}
+	return 0
+}
+
+func (c *Ctx) Bool(name string) bool {
+	if option, ok := c.options[name]; ok {
+		return option.BoolValue()
+	}
+	return false
+}
+
+func (c *Ctx) User(name string) *discordgo.User {
+	if option, ok := c.options[name]; ok {
+		return option.UserValue(c.Session)
+	}
+	return nil
+}
+
+// Focused returns the option the user is typing in during autocomplete.
+func (c *Ctx) Focused() (*Option, bool) {
+	for _, option := range c.options {
+		if option.Focused {
+			return option, true
+		}
+	}
+	return nil, false
+}
+
+// ModalValue returns the value of the text input with the given custom ID.
+func (c *Ctx) ModalValue(customID string) string {
+	for _, row := range c.Interaction.ModalSubmitData().Components {
+		actionsRow, ok := row.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, component := range actionsRow.Components {
+			if input, ok := component.(*discordgo.TextInput); ok && input.CustomID == customID {
+				return input.Value
+			}
+		}
+	}
+	return ""
+}
+
+func (c *Ctx) Respond(resp util.InteractionResponse) error {
+	_, err := c.send(func() error {
+		return util.RespondToInteraction(c.Session, c.Interaction, resp)
+	})
+	return err
+}
+
+func (c *Ctx) Reply(content string) error {
+	return c.Respond(util.InteractionResponse{Content: content})
+}
+
+func (c *Ctx) ReplyEphemeral(content string) error {
+	return c.Respond(util.InteractionResponse{Content: content, Ephemeral: true})
+}
+
+// Defer acknowledges the interaction with the usual "please wait" embed so the
+// handler can take longer than Discord's three second window.
+func (c *Ctx) Defer(title string) error {
+	_, err := c.send(func() error {
+		return util.DeferResponse(c.Session, c.Interaction, util.DeferResponseOptions{
+			Ephemeral: c.Guild.Ephemeral,
+			Embeds: []*discordgo.MessageEmbed{
+				util.NewEmbed(util.StyleDefault, title, "> "+c.T("common.please_wait")).
+					WithFooter(Footer).
+					Build(),
+			},
+		})
+	})
+	if err != nil {
+		c.Log.Error("Error deferring response", "error", err)
+	}
+	return err
+}
+
+// DeferUpdate acknowledges a component interaction; the message it's attached
+// to is then edited in place.
+func (c *Ctx) DeferUpdate() error {
+	_, err := c.send(func() error {
+		return c.Session.InteractionRespond(c.Interaction.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
+		})
+	})
+	if err != nil {
+		c.Log.Error("Error deferring component response", "error", err)
+	}
+	return err
+}
+
+// stopTrackers waits for the progress trackers to make their last edit, so a
+// response sent afterwards isn't overwritten by a late step or error embed.
+func (c *Ctx) stopTrackers() {
+	c.mu.Lock()
+	trackers := c.trackers
+	c.mu.Unlock()
+	for _, tracker := range trackers {
+		tracker.Stop()
+		tracker.Wait()
+	}
+}
+
+// Progress starts a tracker that edits the deferred response as the service
+// reports progress.
+func (c *Ctx) Progress(title string) *util.ProgressTracker {
+	tracker := util.NewProgressTracker(c.Session, c.Interaction.Interaction, title, Footer, util.StyleDefault)
+	tracker.Catalog = c.Catalog
+	tracker.Locale = c.Locale()
+	tracker.ErrorFooter = c.errorFooter()
+	tracker.Guard = &c.sendMu
+	tracker.Metrics = c.Metrics
+
+	c.mu.Lock()
+	c.trackers = append(c.trackers, tracker)
+	if c.Interrupted() {
+		tracker.Silence()
+	}
+	c.mu.Unlock()
+
+	tracker.Start()
+	return tracker
+}
+
+func (c *Ctx) Edit(edit *discordgo.WebhookEdit) error {
+	_, err := c.send(func() error {
+		_, err := c.Session.InteractionResponseEdit(c.Interaction.Interaction, edit)
+		return err
+	})
+	if err != nil {
+		c.Log.Error("Error editing final interaction response", "error", apperrors.Wrap(err, "INTERACTION_EDIT_ERROR", "failed to edit interaction response"))
+	}
+	return err
+}
+
+func (c *Ctx) EditEmbeds(embeds ...*discordgo.MessageEmbed) error {
+	return c.Edit(&discordgo.WebhookEdit{Embeds: &embeds})
+}
+
+func (c *Ctx) Followup(resp util.InteractionResponse) (*discordgo.Message, error) {
+	var msg *discordgo.Message
+	sent, err := c.send(func() (err error) {
+		msg, err = util.FollowUpResponse(c.Session, c.Interaction.Interaction, resp)
+		return err
+	})
+	if !sent {
+		return nil, apperrors.New("INTERACTION_INTERRUPTED", "interaction was interrupted by shutdown")
+	}
+	return msg, err
+}
+
+// Fail logs err and replaces the deferred response with its user message.
+// action describes what failed, e.g. "getting player rank data".
+func (c *Ctx) Fail(err error, action string) {
+	errorMessage, logMessage := c.ErrorMessage(err, action)
+	c.stopTrackers()
+	sent, _ := c.send(func() error {
+		c.Log.Error(logMessage)
+		c.failed = true
+		c.Report(err, action)
+		util.SendErrorEmbed(c.Session, c.Interaction.Interaction, c.T("common.error"), errorMessage, c.Log, c.errorFooter())
+		return nil
+	})
+	if !sent {
+		// the error is almost always the cancelled context
+		c.Log.Debug(logMessage, "interrupted", true)
+	}
+}
+
+// Failed reports whether the handler ended up showing an error.
+func (c *Ctx) Failed() bool {
+	return c.failed
+}
+
+// RecoverPanic, deferred around a handler, turns a panic into a logged and
+// reported failure and tells the user something went wrong. Autocomplete
+// interactions get no reply, as they can only answer with choices.
+func (c *Ctx) RecoverPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	c.Log.Error("Handler panicked", "panic", r, "stack", string(debug.Stack()))
+	c.Report(apperrors.New("PANIC", fmt.Sprintf("handler panicked: %v", r)), "handling "+c.Command())
+	if c.Interaction.Type != discordgo.InteractionApplicationCommandAutocomplete {
+		c.RespondError("An unexpected error occurred. Please try again later.")
+	}
+}
+
+// Report sends err to the error reporter, tagged with this interaction.
+func (c *Ctx) Report(err error, action string) {
+	c.Errors.Report(errorreport.NewOccurrence(err, action, errorreport.Source{
+		Command:       c.Command(),
+		GuildID:       c.Interaction.GuildID,
+		UserID:        c.UserID(),
+		CorrelationID: c.CorrelationID,
+	}))
+}
+
+// errorFooter is the footer of error embeds, which carries the correlation id.
+func (c *Ctx) errorFooter() string {
+	return Footer + " • " + c.T("common.reference", i18n.Vars{"id": c.CorrelationID})
+}
+
+// ErrorMessage is apperrors.HandleError with the user message translated.
+func (c *Ctx) ErrorMessage(err error, action string) (string, string) {
+	errorMessage, logMessage := apperrors.HandleError(err, action)
+	return c.Catalog.Error(c.Locale(), errorMessage), logMessage
+}
+
+// RespondError shows message in an ephemeral error embed, editing the original
+// response instead if the interaction was already acknowledged. English
+// error messages from the catalog are translated.
+func (c *Ctx) RespondError(message string) {
+	message = c.Catalog.Error(c.Locale(), message)
+	c.stopTrackers()
+	errorEmbed := util.NewEmbed(util.StyleError, c.T("common.error"), message).
+		WithFooter(c.errorFooter()).
+		Build()
+
+	c.send(func() error {
+		c.failed = true
+		err := util.RespondToInteraction(c.Session, c.Interaction, util.InteractionResponse{
+			Embeds:    []*discordgo.MessageEmbed{errorEmbed},
+			Ephemeral: true,
+		})
+		var restErr *discordgo.RESTError
+		if err != nil && errors.As(err, &restErr) {
+			util.SendErrorEmbed(c.Session, c.Interaction.Interaction, c.T("common.error"), message, c.Log, c.errorFooter())
+		}
+		return nil
+	})
+}
@@ -1,8 +1,9 @@
 package logger
 
 import (
+	"context"
+	"io"
 	"os"
-	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -12,75 +13,110 @@ type Logger struct {
 	*log.Logger
 }
 
-var (
-	instance *Logger
-	once     sync.Once
-)
+type options struct {
+	log.Options
+	file       string
+	maxSize    int64
+	maxBackups int
+}
 
-type Option func(*log.Options)
+type Option func(*options)
 
 func WithLevel(level log.Level) Option {
-	return func(o *log.Options) {
+	return func(o *options) {
 		o.Level = level
 	}
 }
 
 func WithTimeFormat(format string) Option {
-	return func(o *log.Options) {
+	return func(o *options) {
 		o.TimeFormat = format
 	}
 }
 
 func WithPrefix(prefix string) Option {
-	return func(o *log.Options) {
+	return func(o *options) {
 		o.Prefix = prefix
 	}
 }
 
+// WithFormat picks the output format: "json", "logfmt" or the default
+// human-readable text.
+func WithFormat(format string) Option {
+	return func(o *options) {
+		switch format {
+		case "json":
+			o.Formatter = log.JSONFormatter
+		case "logfmt":
+			o.Formatter = log.LogfmtFormatter
+		default:
+			o.Formatter = log.TextFormatter
+		}
+	}
+}
+
+// WithFile also writes every entry to path, rotating it once it grows past
+// maxSizeMB and keeping maxBackups old files around.
+func WithFile(path string, maxSizeMB, maxBackups int) Option {
+	return func(o *options) {
+		o.file = path
+		o.maxSize = int64(maxSizeMB) << 20
+		o.maxBackups = maxBackups
+	}
+}
+
 func NewLogger(opts ...Option) *Logger {
-	once.Do(func() {
-		options := log.Options{
+	options := options{
+		Options: log.Options{
 			ReportCaller:    true,
 			ReportTimestamp: true,
 			TimeFormat:      time.RFC3339,
 			Prefix:          "yko|",
 			Level:           log.InfoLevel,
-		}
-
-		for _, opt := range opts {
-			opt(&options)
-		}
-
-		logger := log.NewWithOptions(os.Stderr, options)
-		instance = &Logger{Logger: logger}
-	})
+		},
+	}
 
-	return instance
-}
+	for _, opt := range opts {
+		opt(&options)
+	}
 
-func GetLogger() *Logger {
-	if instance == nil {
-		return NewLogger()
+	var out io.Writer = os.Stderr
+	var fileErr error
+	if options.file != "" {
+		file, err := newRotatingFile(options.file, options.maxSize, options.maxBackups)
+		if err != nil {
+			fileErr = err
+		} else {
+			out = io.MultiWriter(os.Stderr, file)
+		}
 	}
-	return instance
-}
 
-func Info(msg string, keyvals ...interface{}) {
-	GetLogger().Info(msg, keyvals...)
+	logger := &Logger{Logger: log.NewWithOptions(out, options.Options)}
+	if fileErr != nil {
+		logger.Warn("Failed to open log file, logging to stderr only", "file", options.file, "error", fileErr)
+	}
+	return logger
 }
 
-func Error(msg string, keyvals ...interface{}) {
-	GetLogger().Error(msg, keyvals...)
+// With returns a child logger that adds keyvals to every entry.
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	return &Logger{Logger: l.Logger.With(keyvals...)}
 }
 
-func Debug(msg string, keyvals ...interface{}) {
-	GetLogger().Debug(msg, keyvals...)
-}
+type contextKey struct{}
 
-func Warn(msg string, keyvals ...interface{}) {
-	GetLogger().Warn(msg, keyvals...)
+// NewContext returns a copy of ctx carrying l, so code further down the call
+// chain logs with the same fields (such as the correlation id).
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
 }
 
-func Fatal(msg string, keyvals ...interface{}) {
-	GetLogger().Fatal(msg, keyvals...)
+// FromContext returns the logger stored in ctx, or fallback if there is none.
+func FromContext(ctx context.Context, fallback *Logger) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return fallback
 }
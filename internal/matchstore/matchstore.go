@@ -0,0 +1,201 @@
+package matchstore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/database"
+	"yk-dc-bot/internal/henrikapi"
+	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/models"
+	"yk-dc-bot/internal/tracing"
+)
+
+type Store struct {
+	db  *database.Database
+	log *logger.Logger
+}
+
+func NewMatchStore(db *database.Database, log *logger.Logger) *Store {
+	return &Store{db: db, log: log}
+}
+
+// startSpan traces a query against the match tables.
+func startSpan(ctx context.Context, name string, attrs ...tracing.Attribute) (context.Context, *tracing.Span) {
+	return tracing.StartClient(ctx, "db "+name, append([]tracing.Attribute{tracing.Attr("db.system", "postgresql")}, attrs...)...)
+}
+
+func endSpan(span *tracing.Span, err *error) {
+	span.SetError(*err)
+	span.End()
+}
+
+func (s *Store) SaveMatches(ctx context.Context, matches []henrikapi.MatchData) (err error) {
+	ctx, span := startSpan(ctx, "save matches", tracing.Attr("match_count", len(matches)))
+	defer endSpan(span, &err)
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return apperrors.Wrap(err, "MATCH_STORE_ERROR", "failed to begin match store transaction")
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	for idx := range matches {
+		match := &matches[idx]
+		if match.Metadata.MatchID == "" {
+			continue
+		}
+
+		_, err := tx.NamedExecContext(ctx, `
+			INSERT INTO matches (match_id, map, mode, rounds, red_score, blue_score, started_at, stored_at)
+			VALUES (:match_id, :map, :mode, :rounds, :red_score, :blue_score, :started_at, :stored_at)
+			ON CONFLICT (match_id) DO NOTHING
+		`, models.Match{
+			MatchID:   match.Metadata.MatchID,
+			Map:       match.Metadata.Map,
+			Mode:      match.Metadata.Mode,
+			Rounds:    len(match.Rounds),
+			RedScore:  match.Teams.Red.RoundsWon,
+			BlueScore: match.Teams.Blue.RoundsWon,
+			StartedAt: time.Unix(match.Metadata.GameStart, 0).UTC(),
+			StoredAt:  now,
+		})
+		if err != nil {
+			return apperrors.Wrap(err, "MATCH_STORE_ERROR", "failed to store match "+match.Metadata.MatchID)
+		}
+
+		for _, player := range match.Players.AllPlayers {
+			_, err := tx.NamedExecContext(ctx, `
+				INSERT INTO match_players (match_id, puuid, name, tag, team, agent, party_id, kills, deaths, assists, score, damage, won)
+				VALUES (:match_id, :puuid, :name, :tag, :team, :agent, :party_id, :kills, :deaths, :assists, :score, :damage, :won)
+				ON CONFLICT (match_id, puuid) DO NOTHING
+			`, models.MatchPlayer{
+				MatchID: match.Metadata.MatchID,
+				Puuid:   player.Puuid,
+				Name:    player.Name,
+				Tag:     player.Tag,
+				Team:    strings.ToLower(player.Team),
+				Agent:   player.Character,
+				PartyID: player.PartyID,
+				Kills:   player.Stats.Kills,
+				Deaths:  player.Stats.Deaths,
+				Assists: player.Stats.Assists,
+				Score:   player.Stats.Score,
+				Damage:  player.DamageMade,
+				Won:     match.Team(player.Team).HasWon,
+			})
+			if err != nil {
+				return apperrors.Wrap(err, "MATCH_STORE_ERROR", "failed to store match player for "+match.Metadata.MatchID)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperrors.Wrap(err, "MATCH_STORE_ERROR", "failed to commit match store transaction")
+	}
+	return nil
+}
+
+type SharedMatch struct {
+	MatchID   string    `db:"match_id"`
+	Map       string    `db:"map"`
+	StartedAt time.Time `db:"started_at"`
+	SameTeam  bool      `db:"same_team"`
+
+	AWon     bool   `db:"a_won"`
+	AAgent   string `db:"a_agent"`
+	AKills   int    `db:"a_kills"`
+	ADeaths  int    `db:"a_deaths"`
+	AAssists int    `db:"a_assists"`
+	AScore   int    `db:"a_score"`
+
+	BWon     bool   `db:"b_won"`
+	BAgent   string `db:"b_agent"`
+	BKills   int    `db:"b_kills"`
+	BDeaths  int    `db:"b_deaths"`
+	BAssists int    `db:"b_assists"`
+	BScore   int    `db:"b_score"`
+}
+
+func (s *Store) SharedMatches(ctx context.Context, puuidA, puuidB string) (_ []SharedMatch, err error) {
+	ctx, span := startSpan(ctx, "shared matches")
+	defer endSpan(span, &err)
+
+	var shared []SharedMatch
+	err = s.db.SelectContext(ctx, &shared, `
+		SELECT
+			m.match_id, m.map, m.started_at, a.team = b.team AS same_team,
+			a.won AS a_won, a.agent AS a_agent, a.kills AS a_kills, a.deaths AS a_deaths, a.assists AS a_assists, a.score AS a_score,
+			b.won AS b_won, b.agent AS b_agent, b.kills AS b_kills, b.deaths AS b_deaths, b.assists AS b_assists, b.score AS b_score
+		FROM match_players a
+		JOIN match_players b ON b.match_id = a.match_id
+		JOIN matches m ON m.match_id = a.match_id
+		WHERE a.puuid = $1 AND b.puuid = $2
+		ORDER BY m.started_at DESC
+	`, puuidA, puuidB)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "MATCH_STORE_QUERY_ERROR", "failed to query shared matches")
+	}
+	return shared, nil
+}
+
+type PartyMember struct {
+	Puuid string
+	Name  string
+	Tag   string
+}
+
+type PartyMatch struct {
+	MatchID   string
+	StartedAt time.Time
+	Won       bool
+	Members   []PartyMember
+}
+
+func (s *Store) PartyMatches(ctx context.Context, puuid string, limit int) (_ []PartyMatch, err error) {
+	ctx, span := startSpan(ctx, "party matches", tracing.Attr("limit", limit))
+	defer endSpan(span, &err)
+
+	var rows []struct {
+		MatchID   string    `db:"match_id"`
+		StartedAt time.Time `db:"started_at"`
+		Won       bool      `db:"won"`
+		Puuid     *string   `db:"member_puuid"`
+		Name      *string   `db:"member_name"`
+		Tag       *string   `db:"member_tag"`
+	}
+	err = s.db.SelectContext(ctx, &rows, `
+		SELECT p.match_id, m.started_at, p.won,
+			t.puuid AS member_puuid, t.name AS member_name, t.tag AS member_tag
+		FROM match_players p
+		JOIN matches m ON m.match_id = p.match_id
+		LEFT JOIN match_players t
+			ON t.match_id = p.match_id AND t.party_id = p.party_id AND t.party_id <> '' AND t.puuid <> p.puuid
+		WHERE p.puuid = $1 AND p.match_id IN (
+			SELECT mp.match_id FROM match_players mp
+			JOIN matches mm ON mm.match_id = mp.match_id
+			WHERE mp.puuid = $1
+			ORDER BY mm.started_at DESC
+			LIMIT $2
+		)
+		ORDER BY m.started_at DESC, p.match_id
+	`, puuid, limit)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "MATCH_STORE_QUERY_ERROR", "failed to query party matches")
+	}
+
+	var matches []PartyMatch
+	for _, row := range rows {
+		if len(matches) == 0 || matches[len(matches)-1].MatchID != row.MatchID {
+			matches = append(matches, PartyMatch{MatchID: row.MatchID, StartedAt: row.StartedAt, Won: row.Won})
+		}
+		if row.Puuid != nil {
+			current := &matches[len(matches)-1]
+			current.Members = append(current.Members, PartyMember{Puuid: *row.Puuid, Name: *row.Name, Tag: *row.Tag})
+		}
+	}
+	return matches, nil
+}
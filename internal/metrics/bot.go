@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	commandBuckets  = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+	upstreamBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+)
+
+// Bot holds the metrics the bot records as it handles interactions. Every
+// method is safe to call on a nil Bot, so code built without metrics, such as
+// the register tool or a test, records nothing.
+type Bot struct {
+	commandInvocations *CounterVec
+	commandDuration    *HistogramVec
+	upstreamRequests   *CounterVec
+	upstreamDuration   *HistogramVec
+	cacheLookups       *CounterVec
+	progressEdits      *CounterVec
+}
+
+func NewBot(registry *Registry) *Bot {
+	registry.NewGaugeFunc("yko_goroutines", "Number of goroutines currently running.", nil, func(emit Emit) {
+		emit(float64(runtime.NumGoroutine()))
+	})
+
+	return &Bot{
+		commandInvocations: registry.NewCounterVec("yko_command_invocations_total",
+			"Slash command invocations by command and outcome (ok, error or panic).",
+			"command", "outcome"),
+		commandDuration: registry.NewHistogramVec("yko_command_duration_seconds",
+			"Time from receiving a slash command to its handler returning.",
+			commandBuckets, "command", "outcome"),
+
+		upstreamRequests: registry.NewCounterVec("yko_upstream_requests_total",
+			"Requests to upstream APIs by provider, endpoint and HTTP status (\"error\" when no response arrived).",
+			"provider", "endpoint", "status"),
+		upstreamDuration: registry.NewHistogramVec("yko_upstream_request_duration_seconds",
+			"Upstream API request latency by provider and endpoint.",
+			upstreamBuckets, "provider", "endpoint"),
+
+		cacheLookups: registry.NewCounterVec("yko_cache_lookups_total",
+			"Redis cache lookups by key prefix and result (hit, miss or error).",
+			"prefix", "result"),
+
+		progressEdits: registry.NewCounterVec("yko_progress_edits_total",
+			"Interaction edits made by progress trackers, by kind (step or error) and result.",
+			"kind", "result"),
+	}
+}
+
+// ObserveCommand records one slash command that started at start.
+func (b *Bot) ObserveCommand(command, outcome string, start time.Time) {
+	if b == nil {
+		return
+	}
+	b.commandInvocations.Inc(command, outcome)
+	b.commandDuration.Observe(time.Since(start).Seconds(), command, outcome)
+}
+
+// ObserveUpstream records one upstream request. status is the HTTP status, or
+// 0 if the request failed before a response arrived.
+func (b *Bot) ObserveUpstream(provider, endpoint string, status int, start time.Time) {
+	if b == nil {
+		return
+	}
+	label := "error"
+	if status != 0 {
+		label = strconv.Itoa(status)
+	}
+	b.upstreamRequests.Inc(provider, endpoint, label)
+	b.upstreamDuration.Observe(time.Since(start).Seconds(), provider, endpoint)
+}
+
+// ObserveCacheLookup records a cache lookup's result: hit, miss or error.
+func (b *Bot) ObserveCacheLookup(prefix, result string) {
+	if b == nil {
+		return
+	}
+	b.cacheLookups.Inc(prefix, result)
+}
+
+// ObserveProgressEdit records an edit made by a progress tracker.
+func (b *Bot) ObserveProgressEdit(kind, result string) {
+	if b == nil {
+		return
+	}
+	b.progressEdits.Inc(kind, result)
+}
+
+// CachePrefix is the part of a cache key before the first colon, e.g.
+// "account" for "account:name#tag".
+func CachePrefix(key string) string {
+	prefix, _, _ := strings.Cut(key, ":")
+	return prefix
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/logger"
+)
+
+// Server serves /metrics, and the other operational endpoints added with
+// Handle such as the health probes, on METRICS_ADDR.
+type Server struct {
+	addr   string
+	log    *logger.Logger
+	mux    *http.ServeMux
+	server *http.Server
+}
+
+func NewServer(cfg *config.Config, registry *Registry, log *logger.Logger) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", registry.Handler())
+	return &Server{addr: cfg.MetricsAddr, log: log, mux: mux}
+}
+
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Start listens on the configured address. With no address it does nothing.
+func (s *Server) Start() error {
+	if s.addr == "" {
+		s.log.Info("METRICS_ADDR is empty, not serving metrics")
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return apperrors.Wrap(err, "METRICS_LISTEN_ERROR", "failed to listen on "+s.addr)
+	}
+	s.server = &http.Server{Handler: s.mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Error("Metrics server stopped", "error", err)
+		}
+	}()
+	s.log.Info("Serving metrics", "addr", listener.Addr().String())
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Shutdown(ctx)
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// ErrorOccurrence is one reported failure of a command or component.
+type ErrorOccurrence struct {
+	ID            int64     `db:"id"`
+	Code          string    `db:"code"`
+	Fingerprint   string    `db:"fingerprint"`
+	Message       string    `db:"message"`
+	Chain         string    `db:"chain"`
+	Command       string    `db:"command"`
+	GuildID       string    `db:"guild_id"`
+	UserID        string    `db:"user_id"`
+	CorrelationID string    `db:"correlation_id"`
+	OccurredAt    time.Time `db:"occurred_at"`
+}
+
+func (ErrorOccurrence) TableName() string {
+	return "error_occurrences"
+}
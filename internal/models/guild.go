@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// GuildSetting is one per-guild setting override; missing rows mean the
+// default applies.
+type GuildSetting struct {
+	ID        int64     `db:"id"`
+	GuildID   string    `db:"guild_id"`
+	Name      string    `db:"name"`
+	Value     string    `db:"value"`
+	UpdatedBy string    `db:"updated_by"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (GuildSetting) TableName() string {
+	return "guild_settings"
+}
+
+func (GuildSetting) TableConstraints() []string {
+	return []string{"UNIQUE (guild_id, name)"}
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+type Match struct {
+	ID        int64     `db:"id"`
+	MatchID   string    `db:"match_id"`
+	Map       string    `db:"map"`
+	Mode      string    `db:"mode"`
+	Rounds    int       `db:"rounds"`
+	RedScore  int       `db:"red_score"`
+	BlueScore int       `db:"blue_score"`
+	StartedAt time.Time `db:"started_at"`
+	StoredAt  time.Time `db:"stored_at"`
+}
+
+func (Match) TableName() string {
+	return "matches"
+}
+
+func (Match) TableConstraints() []string {
+	return []string{"UNIQUE (match_id)"}
+}
+
+type MatchPlayer struct {
+	ID      int64  `db:"id"`
+	MatchID string `db:"match_id"`
+	Puuid   string `db:"puuid"`
+	Name    string `db:"name"`
+	Tag     string `db:"tag"`
+	Team    string `db:"team"`
+	Agent   string `db:"agent"`
+	PartyID string `db:"party_id"`
+	Kills   int    `db:"kills"`
+	Deaths  int    `db:"deaths"`
+	Assists int    `db:"assists"`
+	Score   int    `db:"score"`
+	Damage  int    `db:"damage"`
+	Won     bool   `db:"won"`
+}
+
+func (MatchPlayer) TableName() string {
+	return "match_players"
+}
+
+func (MatchPlayer) TableConstraints() []string {
+	return []string{"UNIQUE (match_id, puuid)"}
+}
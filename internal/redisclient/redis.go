@@ -8,16 +8,19 @@ import (
 	"yk-dc-bot/internal/apperrors"
 	"yk-dc-bot/internal/config"
 	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/metrics"
+	"yk-dc-bot/internal/tracing"
 
 	"github.com/redis/go-redis/v9"
 )
 
 type Client struct {
-	rdb *redis.Client
-	log *logger.Logger
+	rdb     *redis.Client
+	log     *logger.Logger
+	metrics *metrics.Bot
 }
 
-func NewRedisClient(cfg *config.Config, log *logger.Logger) (*Client, error) {
+func NewRedisClient(cfg *config.Config, log *logger.Logger, m *metrics.Bot) (*Client, error) {
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port),
 		Password: cfg.Redis.Password,
@@ -33,7 +36,7 @@ func NewRedisClient(cfg *config.Config, log *logger.Logger) (*Client, error) {
 	}
 
 	log.Info("Successfully connected to Redis")
-	return &Client{rdb: rdb, log: log}, nil
+	return &Client{rdb: rdb, log: log, metrics: m}, nil
 }
 
 func (c *Client) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
@@ -45,7 +48,22 @@ func (c *Client) Set(ctx context.Context, key string, value interface{}, expirat
 }
 
 func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	prefix := metrics.CachePrefix(key)
+	ctx, span := tracing.StartClient(ctx, "cache get "+prefix, tracing.Attr("db.system", "redis"), tracing.Attr("cache.key", key))
+	defer span.End()
+
 	value, err := c.rdb.Get(ctx, key).Result()
+	switch {
+	case err == redis.Nil:
+		c.metrics.ObserveCacheLookup(prefix, "miss")
+		span.SetAttributes(tracing.Attr("cache.result", "miss"))
+	case err != nil:
+		c.metrics.ObserveCacheLookup(prefix, "error")
+		span.SetError(err)
+	default:
+		c.metrics.ObserveCacheLookup(prefix, "hit")
+		span.SetAttributes(tracing.Attr("cache.result", "hit"))
+	}
 	if err == redis.Nil {
 		return "", apperrors.New("REDIS_CACHE_MISS", fmt.Sprintf("Cache miss for key: %s", key))
 	} else if err != nil {
@@ -54,6 +72,121 @@ func (c *Client) Get(ctx context.Context, key string) (string, error) {
 	return value, nil
 }
 
+func (c *Client) Del(ctx context.Context, keys ...string) error {
+	if err := c.rdb.Del(ctx, keys...).Err(); err != nil {
+		return apperrors.Wrap(err, "REDIS_DEL_ERROR", fmt.Sprintf("Failed to delete keys: %v", keys))
+	}
+	return nil
+}
+
+func (c *Client) Publish(ctx context.Context, channel, message string) error {
+	if err := c.rdb.Publish(ctx, channel, message).Err(); err != nil {
+		return apperrors.Wrap(err, "REDIS_PUBLISH_ERROR", fmt.Sprintf("Failed to publish to channel: %s", channel))
+	}
+	return nil
+}
+
+// Subscribe delivers messages published to channel until ctx is done. The
+// subscription reconnects on its own if the connection drops.
+func (c *Client) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	pubsub := c.rdb.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, apperrors.Wrap(err, "REDIS_SUBSCRIBE_ERROR", fmt.Sprintf("Failed to subscribe to channel: %s", channel))
+	}
+
+	messages := make(chan string)
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+		incoming := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-incoming:
+				if !ok {
+					return
+				}
+				select {
+				case messages <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return messages, nil
+}
+
+// Bucket is a token bucket holding up to Capacity tokens, refilled evenly so
+// it fills up once per Period.
+type Bucket struct {
+	Key      string
+	Capacity int
+	Period   time.Duration
+}
+
+// takeTokensScript refills the buckets in KEYS, then takes a token from each
+// if every one has a token to give. ARGV holds each bucket's capacity and
+// period in milliseconds, in KEYS order. It returns 0 when the tokens were
+// taken, and otherwise how many milliseconds until they all have one.
+var takeTokensScript = redis.NewScript(`
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local levels = {}
+local wait = 0
+for i, key in ipairs(KEYS) do
+	local capacity = tonumber(ARGV[i * 2 - 1])
+	local period = tonumber(ARGV[i * 2])
+	local state = redis.call("HMGET", key, "tokens", "at")
+	local tokens = tonumber(state[1]) or capacity
+	local at = tonumber(state[2]) or now
+	tokens = math.min(capacity, tokens + math.max(0, now - at) * capacity / period)
+	levels[i] = tokens
+	if tokens < 1 then
+		wait = math.max(wait, math.ceil((1 - tokens) * period / capacity))
+	end
+end
+if wait > 0 then
+	return wait
+end
+for i, key in ipairs(KEYS) do
+	redis.call("HSET", key, "tokens", tostring(levels[i] - 1), "at", now)
+	redis.call("PEXPIRE", key, ARGV[i * 2])
+end
+return 0
+`)
+
+// TakeTokens takes a token from every bucket in one step, or from none of
+// them when any is empty, in which case retryAfter is when all of them will
+// have a token again.
+func (c *Client) TakeTokens(ctx context.Context, buckets []Bucket) (bool, time.Duration, error) {
+	if len(buckets) == 0 {
+		return true, 0, nil
+	}
+
+	keys := make([]string, 0, len(buckets))
+	args := make([]interface{}, 0, 2*len(buckets))
+	for _, bucket := range buckets {
+		keys = append(keys, bucket.Key)
+		args = append(args, max(bucket.Capacity, 1), max(bucket.Period.Milliseconds(), 1))
+	}
+
+	wait, err := takeTokensScript.Run(ctx, c.rdb, keys, args...).Int64()
+	if err != nil {
+		return false, 0, apperrors.Wrap(err, "REDIS_RATE_LIMIT_ERROR", fmt.Sprintf("Failed to take tokens from %d buckets", len(buckets)))
+	}
+	if wait > 0 {
+		return false, time.Duration(wait) * time.Millisecond, nil
+	}
+	return true, 0, nil
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	return c.rdb.Ping(ctx).Err()
+}
+
 func (c *Client) Close() error {
 	return c.rdb.Close()
 }
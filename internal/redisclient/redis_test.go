@@ -0,0 +1,83 @@
+package redisclient
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/logger"
+)
+
+// newTestClient connects to the Redis at REDIS_TEST_ADDR. The fake in testkit
+// can't run Lua, so the scripts are only checked against a real server.
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR not set")
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("REDIS_TEST_ADDR: %v", err)
+	}
+	client, err := NewRedisClient(&config.Config{Redis: config.RedisConfig{Host: host, Port: port}}, logger.NewLogger(), nil)
+	if err != nil {
+		t.Fatalf("connecting to redis: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func testBucket(t *testing.T, client *Client, capacity int, period time.Duration) Bucket {
+	t.Helper()
+	key := "test:bucket:" + t.Name() + ":" + time.Now().Format(time.RFC3339Nano)
+	t.Cleanup(func() { client.Del(context.Background(), key) })
+	return Bucket{Key: key, Capacity: capacity, Period: period}
+}
+
+func TestTakeTokensAllowsBurstThenRefills(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	bucket := testBucket(t, client, 2, time.Minute)
+
+	for n := 1; n <= 2; n++ {
+		ok, _, err := client.TakeTokens(ctx, []Bucket{bucket})
+		if err != nil || !ok {
+			t.Fatalf("take %d = %v, %v, want it allowed", n, ok, err)
+		}
+	}
+
+	ok, retryAfter, err := client.TakeTokens(ctx, []Bucket{bucket})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("third take allowed, want the bucket empty")
+	}
+	// one token comes back every period/capacity
+	if retryAfter <= 0 || retryAfter > 30*time.Second {
+		t.Errorf("retryAfter = %v, want at most 30s", retryAfter)
+	}
+}
+
+func TestTakeTokensSpendsNothingWhenRejected(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	user := testBucket(t, client, 1, time.Minute)
+	guild := testBucket(t, client, 1, time.Minute)
+
+	if ok, _, err := client.TakeTokens(ctx, []Bucket{guild}); err != nil || !ok {
+		t.Fatalf("emptying the guild bucket = %v, %v", ok, err)
+	}
+	if ok, _, err := client.TakeTokens(ctx, []Bucket{user, guild}); err != nil || ok {
+		t.Fatalf("take with an empty guild bucket = %v, %v, want it rejected", ok, err)
+	}
+
+	// the rejected take must have left the user's token alone
+	if ok, _, err := client.TakeTokens(ctx, []Bucket{user}); err != nil || !ok {
+		t.Errorf("user bucket after a rejected take = %v, %v, want a token left", ok, err)
+	}
+}
@@ -0,0 +1,213 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"strings"
+
+	"yk-dc-bot/internal/analysis"
+	"yk-dc-bot/internal/apperrors"
+
+	"github.com/fogleman/gg"
+)
+
+const (
+	economyWidth  = 1100
+	economyHeight = 640
+
+	economyLeft   = 70.0
+	economyRight  = 30.0
+	economyTop    = 60.0
+	economyBottom = 380.0
+
+	probabilityTop    = 450.0
+	probabilityBottom = 560.0
+)
+
+var (
+	ColorRedTeam  = color.NRGBA{R: 0xFF, G: 0x46, B: 0x55, A: 0xFF}
+	ColorBlueTeam = color.NRGBA{R: 0x4F, G: 0xC1, B: 0xE9, A: 0xFF}
+
+	colorBackground = color.NRGBA{R: 0x1E, G: 0x1F, B: 0x22, A: 0xFF}
+	colorGrid       = color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0x22}
+	colorText       = color.NRGBA{R: 0xDB, G: 0xDE, B: 0xE1, A: 0xFF}
+)
+
+var buyLabels = map[string]string{
+	analysis.BuyPistol: "P",
+	analysis.BuyEco:    "E",
+	analysis.BuyForce:  "F",
+	analysis.BuyFull:   "B",
+}
+
+var outcomeLabels = map[string]string{
+	analysis.OutcomeElimination: "E",
+	analysis.OutcomeSpike:       "S",
+	analysis.OutcomeDefuse:      "D",
+	analysis.OutcomeTime:        "T",
+	analysis.OutcomeSurrender:   "FF",
+}
+
+func EconomyChart(title string, rounds []analysis.RoundEconomy) ([]byte, error) {
+	if len(rounds) == 0 {
+		return nil, apperrors.New("RENDER_NO_DATA", "no rounds to chart")
+	}
+
+	dc := gg.NewContext(economyWidth, economyHeight)
+	dc.SetColor(colorBackground)
+	dc.Clear()
+
+	plotWidth := economyWidth - economyLeft - economyRight
+	slot := plotWidth / float64(len(rounds))
+	slotX := func(round int) float64 { return economyLeft + slot*float64(round) }
+
+	dc.SetColor(colorText)
+	dc.DrawString(title, economyLeft, 28)
+	drawLegend(dc, economyWidth-economyRight, 28)
+
+	// economy panel: loadout bars and banked credits
+	maxValue := 5000
+	for _, r := range rounds {
+		maxValue = max(maxValue, r.Red.Loadout, r.Blue.Loadout, r.Red.Credits, r.Blue.Credits)
+	}
+	maxValue = (maxValue/5000 + 1) * 5000
+	valueY := func(v int) float64 {
+		return economyBottom - (economyBottom-economyTop)*float64(v)/float64(maxValue)
+	}
+
+	dc.SetLineWidth(1)
+	for v := 0; v <= maxValue; v += 5000 {
+		y := valueY(v)
+		dc.SetColor(colorGrid)
+		dc.DrawLine(economyLeft, y, economyWidth-economyRight, y)
+		dc.Stroke()
+		dc.SetColor(colorText)
+		dc.DrawStringAnchored(fmt.Sprintf("%dk", v/1000), economyLeft-8, y, 1, 0.35)
+	}
+
+	barWidth := slot * 0.34
+	for idx, r := range rounds {
+		x := slotX(idx) + slot*0.14
+		dc.SetColor(ColorRedTeam)
+		dc.DrawRectangle(x, valueY(r.Red.Loadout), barWidth, economyBottom-valueY(r.Red.Loadout))
+		dc.Fill()
+		dc.SetColor(ColorBlueTeam)
+		dc.DrawRectangle(x+barWidth+2, valueY(r.Blue.Loadout), barWidth, economyBottom-valueY(r.Blue.Loadout))
+		dc.Fill()
+
+		dc.SetColor(ColorRedTeam)
+		dc.DrawStringAnchored(buyLabels[r.Red.Buy], x+barWidth/2, economyBottom+16, 0.5, 0.5)
+		dc.SetColor(ColorBlueTeam)
+		dc.DrawStringAnchored(buyLabels[r.Blue.Buy], x+barWidth*1.5+2, economyBottom+16, 0.5, 0.5)
+	}
+
+	drawCreditLine(dc, rounds, slot, slotX, valueY, ColorRedTeam, func(r analysis.RoundEconomy) int { return r.Red.Credits })
+	drawCreditLine(dc, rounds, slot, slotX, valueY, ColorBlueTeam, func(r analysis.RoundEconomy) int { return r.Blue.Credits })
+
+	// win-probability panel
+	probabilityY := func(p float64) float64 {
+		return probabilityBottom - (probabilityBottom-probabilityTop)*p
+	}
+
+	dc.SetColor(colorText)
+	dc.DrawString("estimated win probability (loadout)", economyLeft, probabilityTop-12)
+	for _, p := range []float64{0, 0.5, 1} {
+		dc.SetColor(colorGrid)
+		dc.DrawLine(economyLeft, probabilityY(p), economyWidth-economyRight, probabilityY(p))
+		dc.Stroke()
+		dc.SetColor(colorText)
+		dc.DrawStringAnchored(fmt.Sprintf("%.0f%%", p*100), economyLeft-8, probabilityY(p), 1, 0.35)
+	}
+
+	dc.SetLineWidth(2)
+	dc.SetColor(ColorRedTeam)
+	for idx, r := range rounds {
+		x, y := slotX(idx)+slot/2, probabilityY(r.RedWinProbability)
+		if idx == 0 {
+			dc.MoveTo(x, y)
+		} else {
+			dc.LineTo(x, y)
+		}
+	}
+	dc.Stroke()
+
+	// round outcomes
+	for idx, r := range rounds {
+		x := slotX(idx) + slot/2
+		winnerColor := ColorBlueTeam
+		if strings.EqualFold(r.Winner, "red") {
+			winnerColor = ColorRedTeam
+		}
+		dc.SetColor(winnerColor)
+		dc.DrawCircle(x, probabilityBottom+30, min(slot*0.4, 11))
+		dc.Fill()
+		dc.SetColor(colorBackground)
+		dc.DrawStringAnchored(outcomeLabels[r.Outcome], x, probabilityBottom+30, 0.5, 0.35)
+		dc.SetColor(colorText)
+		dc.DrawStringAnchored(fmt.Sprintf("%d", idx+1), x, probabilityBottom+56, 0.5, 0.35)
+	}
+
+	if len(rounds) > 12 {
+		x := slotX(12)
+		dc.SetLineWidth(1)
+		dc.SetDash(6, 4)
+		dc.SetColor(colorText)
+		dc.DrawLine(x, economyTop-10, x, probabilityBottom+44)
+		dc.Stroke()
+		dc.SetDash()
+		dc.DrawStringAnchored("half", x, economyTop-18, 0.5, 0)
+	}
+
+	dc.SetColor(colorText)
+	dc.DrawString("buy: P pistol  E eco  F force  B full    outcome: E elimination  S spike  D defuse  T time", economyLeft, economyHeight-8)
+
+	var buf bytes.Buffer
+	if err := dc.EncodePNG(&buf); err != nil {
+		return nil, apperrors.Wrap(err, "RENDER_ENCODE_ERROR", "failed to encode economy chart")
+	}
+	return buf.Bytes(), nil
+}
+
+func drawCreditLine(dc *gg.Context, rounds []analysis.RoundEconomy, slot float64, slotX func(int) float64, valueY func(int) float64, c color.Color, credits func(analysis.RoundEconomy) int) {
+	dc.SetLineWidth(2)
+	dc.SetDash(4, 3)
+	dc.SetColor(c)
+	for idx, r := range rounds {
+		x, y := slotX(idx)+slot/2, valueY(credits(r))
+		if idx == 0 {
+			dc.MoveTo(x, y)
+		} else {
+			dc.LineTo(x, y)
+		}
+	}
+	dc.Stroke()
+	dc.SetDash()
+}
+
+func drawLegend(dc *gg.Context, right, y float64) {
+	entries := []struct {
+		label string
+		color color.Color
+	}{
+		{"red", ColorRedTeam},
+		{"blue", ColorBlueTeam},
+	}
+
+	x := right
+	for idx := len(entries) - 1; idx >= 0; idx-- {
+		entry := entries[idx]
+		w, _ := dc.MeasureString(entry.label)
+		x -= w
+		dc.SetColor(colorText)
+		dc.DrawString(entry.label, x, y)
+		x -= 16
+		dc.SetColor(entry.color)
+		dc.DrawRectangle(x, y-10, 10, 10)
+		dc.Fill()
+		x -= 16
+	}
+
+	dc.SetColor(colorText)
+	dc.DrawStringAnchored("bars: loadout  dashed: credits", x-8, y, 1, 0)
+}
@@ -0,0 +1,118 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+
+	"yk-dc-bot/internal/apperrors"
+
+	"github.com/fogleman/gg"
+	"github.com/nfnt/resize"
+)
+
+const HeatmapSize = 512
+
+var (
+	ColorKills  = color.NRGBA{R: 0x57, G: 0xF2, B: 0x87, A: 0xFF}
+	ColorDeaths = color.NRGBA{R: 0xED, G: 0x42, B: 0x45, A: 0xFF}
+)
+
+// Point is a position on the minimap, normalized to 0..1 on both axes.
+type Point struct {
+	X float64
+	Y float64
+}
+
+type HeatmapLayer struct {
+	Label  string
+	Color  color.NRGBA
+	Points []Point
+}
+
+func Heatmap(minimap image.Image, layers []HeatmapLayer) ([]byte, error) {
+	base := resize.Resize(HeatmapSize, HeatmapSize, minimap, resize.Lanczos3)
+
+	dc := gg.NewContext(HeatmapSize, HeatmapSize)
+	dc.SetColor(color.Black)
+	dc.Clear()
+	dc.DrawImage(base, 0, 0)
+
+	dc.SetRGBA(0, 0, 0, 0.45)
+	dc.DrawRectangle(0, 0, HeatmapSize, HeatmapSize)
+	dc.Fill()
+
+	for _, layer := range layers {
+		if len(layer.Points) == 0 {
+			continue
+		}
+		dc.DrawImage(densityOverlay(layer), 0, 0)
+
+		dc.SetColor(layer.Color)
+		for _, p := range layer.Points {
+			dc.DrawCircle(p.X*HeatmapSize, p.Y*HeatmapSize, 2)
+			dc.Fill()
+		}
+	}
+
+	legendY := float64(HeatmapSize - 12)
+	legendX := 12.0
+	for _, layer := range layers {
+		dc.SetColor(layer.Color)
+		dc.DrawCircle(legendX+4, legendY-4, 4)
+		dc.Fill()
+		dc.SetColor(color.White)
+		dc.DrawString(layer.Label, legendX+14, legendY)
+		w, _ := dc.MeasureString(layer.Label)
+		legendX += w + 30
+	}
+
+	var buf bytes.Buffer
+	if err := dc.EncodePNG(&buf); err != nil {
+		return nil, apperrors.Wrap(err, "RENDER_ENCODE_ERROR", "failed to encode heatmap")
+	}
+	return buf.Bytes(), nil
+}
+
+// densityOverlay splats a gaussian kernel at each point and maps the
+// normalized density to the layer color's alpha.
+func densityOverlay(layer HeatmapLayer) *image.NRGBA {
+	const sigma = 14.0
+	radius := int(sigma * 3)
+
+	density := make([]float64, HeatmapSize*HeatmapSize)
+	maxDensity := 0.0
+
+	for _, p := range layer.Points {
+		cx, cy := int(p.X*HeatmapSize), int(p.Y*HeatmapSize)
+		for y := max(cy-radius, 0); y <= min(cy+radius, HeatmapSize-1); y++ {
+			for x := max(cx-radius, 0); x <= min(cx+radius, HeatmapSize-1); x++ {
+				dx, dy := float64(x-cx), float64(y-cy)
+				idx := y*HeatmapSize + x
+				density[idx] += math.Exp(-(dx*dx + dy*dy) / (2 * sigma * sigma))
+				maxDensity = math.Max(maxDensity, density[idx])
+			}
+		}
+	}
+
+	overlay := image.NewNRGBA(image.Rect(0, 0, HeatmapSize, HeatmapSize))
+	if maxDensity == 0 {
+		return overlay
+	}
+
+	for idx, d := range density {
+		if d == 0 {
+			continue
+		}
+		alpha := math.Sqrt(d/maxDensity) * 0.8
+		overlay.SetNRGBA(idx%HeatmapSize, idx/HeatmapSize, color.NRGBA{
+			R: layer.Color.R,
+			G: layer.Color.G,
+			B: layer.Color.B,
+			A: uint8(alpha * 255),
+		})
+	}
+
+	return overlay
+}
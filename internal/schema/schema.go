@@ -0,0 +1,221 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/logger"
+	redisclient "yk-dc-bot/internal/redisclient"
+
+	"github.com/tidwall/gjson"
+)
+
+type Kind string
+
+const (
+	Any    Kind = "any"
+	String Kind = "string"
+	Number Kind = "number"
+	Bool   Kind = "bool"
+	Object Kind = "object"
+	Array  Kind = "array"
+)
+
+// maxSample caps how much of a drifted payload is kept for debugging.
+const maxSample = 64 << 10
+
+const sampleTTL = 7 * 24 * time.Hour
+
+// Field is a dotted gjson path and the type expected there. Numeric segments
+// index into arrays; when the array is shorter the field is skipped, so an
+// empty match history isn't reported as drift.
+type Field struct {
+	Path     string
+	Kind     Kind
+	Required bool
+}
+
+func Required(path string, kind Kind) Field {
+	return Field{Path: path, Kind: kind, Required: true}
+}
+
+func Optional(path string, kind Kind) Field {
+	return Field{Path: path, Kind: kind}
+}
+
+// Schema is the part of a provider payload the bot relies on.
+type Schema struct {
+	Name   string
+	Fields []Field
+}
+
+func New(name string, fields ...Field) *Schema {
+	return &Schema{Name: name, Fields: fields}
+}
+
+// Problem is a single mismatch between a payload and its schema.
+type Problem struct {
+	Path     string `json:"path"`
+	Want     Kind   `json:"want"`
+	Got      Kind   `json:"got"`
+	Required bool   `json:"required"`
+}
+
+func (p Problem) String() string {
+	if p.Got == "" {
+		return p.Path + " missing"
+	}
+	return fmt.Sprintf("%s is %s, want %s", p.Path, p.Got, p.Want)
+}
+
+// Check lists every field of s that is missing or has the wrong type in body.
+// Optional fields are only reported when present with the wrong type.
+func (s *Schema) Check(body []byte) []Problem {
+	if !gjson.ValidBytes(body) {
+		return []Problem{{Path: "$", Want: Object, Got: "invalid json", Required: true}}
+	}
+
+	root := gjson.ParseBytes(body)
+	var problems []Problem
+	for _, field := range s.Fields {
+		value, ok := lookup(root, field.Path)
+		if !ok {
+			continue
+		}
+		if !value.Exists() || value.Type == gjson.Null {
+			if field.Required {
+				problems = append(problems, Problem{Path: field.Path, Want: field.Kind, Required: true})
+			}
+			continue
+		}
+		if got := kindOf(value); field.Kind != Any && got != field.Kind {
+			problems = append(problems, Problem{Path: field.Path, Want: field.Kind, Got: got, Required: field.Required})
+		}
+	}
+	return problems
+}
+
+// lookup walks path one segment at a time. It returns false when the path
+// indexes past the end of an array, meaning there's nothing to check.
+func lookup(root gjson.Result, path string) (gjson.Result, bool) {
+	value := root
+	for _, segment := range strings.Split(path, ".") {
+		if idx, err := strconv.Atoi(segment); err == nil && value.IsArray() {
+			items := value.Array()
+			if idx >= len(items) {
+				return gjson.Result{}, false
+			}
+			value = items[idx]
+			continue
+		}
+		value = value.Get(gjson.Escape(segment))
+		if !value.Exists() {
+			return value, true
+		}
+	}
+	return value, true
+}
+
+func kindOf(value gjson.Result) Kind {
+	switch {
+	case value.IsObject():
+		return Object
+	case value.IsArray():
+		return Array
+	case value.IsBool():
+		return Bool
+	case value.Type == gjson.Number:
+		return Number
+	default:
+		return String
+	}
+}
+
+// Detector validates provider responses, logging and counting drift and
+// keeping the latest offending payload per schema in Redis.
+type Detector struct {
+	strict      bool
+	redisClient *redisclient.Client
+	log         *logger.Logger
+
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func NewDetector(cfg *config.Config, redisClient *redisclient.Client, log *logger.Logger) *Detector {
+	return &Detector{
+		strict:      cfg.SchemaStrict,
+		redisClient: redisClient,
+		log:         log,
+		counts:      make(map[string]int64),
+	}
+}
+
+// Validate checks body against s. Drift is only an error in strict mode, and
+// then only when a required field is affected; otherwise the caller carries
+// on with whatever it can parse. A nil Detector accepts everything.
+func (d *Detector) Validate(s *Schema, body []byte) error {
+	if d == nil {
+		return nil
+	}
+
+	problems := s.Check(body)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	d.counts[s.Name]++
+	d.mu.Unlock()
+
+	descriptions := make([]string, 0, len(problems))
+	required := false
+	for _, problem := range problems {
+		descriptions = append(descriptions, problem.String())
+		required = required || problem.Required
+	}
+	d.log.Warn("Provider response schema drift", "schema", s.Name, "problems", strings.Join(descriptions, "; "))
+	d.storeSample(s.Name, problems, body)
+
+	if d.strict && required {
+		return apperrors.New("SCHEMA_DRIFT", fmt.Sprintf("%s response doesn't match the expected schema: %s", s.Name, strings.Join(descriptions, "; ")))
+	}
+	return nil
+}
+
+// Counts returns how many drifted responses each schema has seen since start.
+func (d *Detector) Counts() map[string]int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	counts := make(map[string]int64, len(d.counts))
+	for name, count := range d.counts {
+		counts[name] = count
+	}
+	return counts
+}
+
+func (d *Detector) storeSample(name string, problems []Problem, body []byte) {
+	if d.redisClient == nil {
+		return
+	}
+	if len(body) > maxSample {
+		body = body[:maxSample]
+	}
+
+	sample, _ := json.Marshal(struct {
+		At       time.Time `json:"at"`
+		Problems []Problem `json:"problems"`
+		Payload  string    `json:"payload"`
+	}{time.Now().UTC(), problems, string(body)})
+
+	if err := d.redisClient.Set(context.Background(), "schema_drift:"+name, string(sample), sampleTTL); err != nil {
+		d.log.Error("Failed to store schema drift sample", "schema", name, "error", err)
+	}
+}
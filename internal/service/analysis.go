@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"yk-dc-bot/internal/analysis"
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/tracing"
+	"yk-dc-bot/internal/util"
+)
+
+// Analysis breaks down a player's recent matches.
+type Analysis struct {
+	players *Players
+}
+
+func NewAnalysis(players *Players) *Analysis {
+	return &Analysis{players: players}
+}
+
+type AnalysisData struct {
+	AccountName string
+	AccountTag  string
+	CardURL     string
+	Metrics     *analysis.PlayerMetrics
+}
+
+func (s *Analysis) GetPlayerAnalysis(ctx context.Context, name, tag, region string, matchCount int, tradeWindow time.Duration, tracker *util.ProgressTracker) (_ *AnalysisData, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetPlayerAnalysis", tracing.Attr("player", name+"#"+tag), tracing.Attr("match_count", matchCount), tracing.Attr("trade_window_s", int(tradeWindow/time.Second)))
+	defer endSpan(span, &err)
+
+	time.Sleep(500 * time.Millisecond)
+
+	tracker.SendStep("progress.looking_up", i18n.Vars{"player": name + "#" + tag})
+	accountData, err := s.players.lookupAccount(ctx, name, tag, region, tracker)
+	if err != nil {
+		return nil, err
+	}
+
+	tracker.SendStep("progress.recent_matches", i18n.Vars{"count": matchCount})
+	matches, err := s.players.fetchMatches(ctx, accountData.Region, accountData.Puuid, matchCount)
+	if err != nil {
+		tracker.SendError(apperrors.Wrap(err, "MATCH_HISTORY_ERROR", "error fetching match history", "There was an error. Please try again later."))
+		return nil, apperrors.Wrap(err, "MATCH_HISTORY_ERROR", "error fetching match history")
+	}
+
+	if len(matches) == 0 {
+		appErr := apperrors.New("MATCH_HISTORY_EMPTY", "no competitive matches found", "No recent competitive matches found for this player")
+		tracker.SendError(appErr)
+		return nil, appErr
+	}
+
+	tracker.SendStep("progress.crunching")
+	metrics := analysis.AnalyzePlayer(accountData.Puuid, matches, analysis.WithTradeWindow(tradeWindow))
+
+	tracker.SendDone()
+	return &AnalysisData{
+		AccountName: accountData.Name,
+		AccountTag:  accountData.Tag,
+		CardURL:     fmt.Sprintf("https://media.valorant-api.com/playercards/%s/smallart.png", accountData.Card),
+		Metrics:     metrics,
+	}, nil
+}
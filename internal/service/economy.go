@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"yk-dc-bot/internal/analysis"
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/henrikapi"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/render"
+	"yk-dc-bot/internal/tracing"
+	"yk-dc-bot/internal/util"
+)
+
+// Economy charts a match's round-by-round economy.
+type Economy struct {
+	henrik  *henrikapi.HenrikDevAPI
+	players *Players
+}
+
+func NewEconomy(henrik *henrikapi.HenrikDevAPI, players *Players) *Economy {
+	return &Economy{henrik: henrik, players: players}
+}
+
+type EconomyData struct {
+	MatchID   string
+	Map       string
+	RedScore  int
+	BlueScore int
+	Rounds    []analysis.RoundEconomy
+	Image     []byte
+}
+
+func (s *Economy) GetMatchEconomy(ctx context.Context, matchID, name, tag, region string, matchIndex int, tracker *util.ProgressTracker) (_ *EconomyData, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetMatchEconomy", tracing.Attr("player", name+"#"+tag), tracing.Attr("match_id", matchID))
+	defer endSpan(span, &err)
+
+	time.Sleep(500 * time.Millisecond)
+
+	if matchID == "" {
+		tracker.SendStep("progress.finding_match", i18n.Vars{"player": name + "#" + tag})
+		accountData, err := s.players.lookupAccount(ctx, name, tag, region, tracker)
+		if err != nil {
+			return nil, err
+		}
+
+		matches, err := s.players.fetchMatches(ctx, accountData.Region, accountData.Puuid, 10)
+		if err != nil {
+			tracker.SendError(apperrors.Wrap(err, "MATCH_HISTORY_ERROR", "error fetching match history", "There was an error. Please try again later."))
+			return nil, apperrors.Wrap(err, "MATCH_HISTORY_ERROR", "error fetching match history")
+		}
+
+		if matchIndex < 1 || matchIndex > len(matches) {
+			appErr := apperrors.New("MATCH_HISTORY_EMPTY", fmt.Sprintf("match index %d out of range (%d matches)", matchIndex, len(matches)), "That match isn't in their recent competitive history")
+			tracker.SendError(appErr)
+			return nil, appErr
+		}
+
+		matchID = matches[matchIndex-1].Metadata.MatchID
+	}
+
+	tracker.SendStep("progress.round_details")
+	match, err := s.henrik.GetMatchByID(ctx, matchID)
+	if err != nil {
+		appErr := apperrors.Wrap(err, "MATCH_DETAILS_ERROR", "error fetching match details", "There was an error. Please try again later.")
+		var fetchErr *apperrors.AppError
+		if errors.As(err, &fetchErr) && fetchErr.UserMessage != "" {
+			appErr.UserMessage = fetchErr.UserMessage
+		}
+		tracker.SendError(appErr)
+		return nil, appErr
+	}
+
+	s.players.saveMatches(ctx, []henrikapi.MatchData{*match})
+
+	tracker.SendStep("progress.economy_chart")
+	rounds := analysis.MatchEconomy(match)
+	title := fmt.Sprintf("%s  •  red %d - %d blue", match.Metadata.Map, match.Teams.Red.RoundsWon, match.Teams.Blue.RoundsWon)
+	img, err := render.EconomyChart(title, rounds)
+	if err != nil {
+		tracker.SendError(apperrors.Wrap(err, "ECONOMY_RENDER_ERROR", "error rendering economy chart", "There was an error. Please try again later."))
+		return nil, apperrors.Wrap(err, "ECONOMY_RENDER_ERROR", "error rendering economy chart")
+	}
+
+	tracker.SendDone()
+	return &EconomyData{
+		MatchID:   match.Metadata.MatchID,
+		Map:       match.Metadata.Map,
+		RedScore:  match.Teams.Red.RoundsWon,
+		BlueScore: match.Teams.Blue.RoundsWon,
+		Rounds:    rounds,
+		Image:     img,
+	}, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/henrikapi"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/matchstore"
+	"yk-dc-bot/internal/tracing"
+	"yk-dc-bot/internal/util"
+)
+
+// HeadToHead compares two players over the matches they shared.
+type HeadToHead struct {
+	players *Players
+	store   *matchstore.Store
+}
+
+func NewHeadToHead(players *Players, store *matchstore.Store) *HeadToHead {
+	return &HeadToHead{players: players, store: store}
+}
+
+type HeadToHeadLine struct {
+	Kills   int
+	Deaths  int
+	Assists int
+	Score   int
+}
+
+type HeadToHeadGroup struct {
+	Games int
+	AWins int
+	A     HeadToHeadLine
+	B     HeadToHeadLine
+}
+
+type HeadToHeadData struct {
+	AName    string
+	BName    string
+	Together HeadToHeadGroup
+	Against  HeadToHeadGroup
+	Recent   []matchstore.SharedMatch
+}
+
+func (s *HeadToHead) GetHeadToHead(ctx context.Context, nameA, tagA, nameB, tagB, region string, tracker *util.ProgressTracker) (_ *HeadToHeadData, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetHeadToHead", tracing.Attr("player", nameA+"#"+tagA), tracing.Attr("opponent", nameB+"#"+tagB))
+	defer endSpan(span, &err)
+
+	time.Sleep(500 * time.Millisecond)
+
+	tracker.SendStep("progress.looking_up_both", i18n.Vars{"a": nameA + "#" + tagA, "b": nameB + "#" + tagB})
+	accountA, err := s.players.lookupAccount(ctx, nameA, tagA, region, tracker)
+	if err != nil {
+		return nil, err
+	}
+	accountB, err := s.players.lookupAccount(ctx, nameB, tagB, region, tracker)
+	if err != nil {
+		return nil, err
+	}
+
+	if accountA.Puuid == accountB.Puuid {
+		appErr := apperrors.New("H2H_SAME_PLAYER", "head-to-head requested for the same account", "Those are the same player")
+		tracker.SendError(appErr)
+		return nil, appErr
+	}
+
+	tracker.SendStep("progress.sync_both")
+	for _, account := range []*henrikapi.AccountData{accountA, accountB} {
+		if _, err := s.players.fetchMatches(ctx, account.Region, account.Puuid, 10); err != nil {
+			tracker.SendError(apperrors.Wrap(err, "MATCH_HISTORY_ERROR", "error fetching match history", "There was an error. Please try again later."))
+			return nil, apperrors.Wrap(err, "MATCH_HISTORY_ERROR", "error fetching match history")
+		}
+	}
+
+	tracker.SendStep("progress.shared_games")
+	shared, err := s.store.SharedMatches(ctx, accountA.Puuid, accountB.Puuid)
+	if err != nil {
+		tracker.SendError(apperrors.Wrap(err, "H2H_QUERY_ERROR", "error querying shared matches", "There was an error. Please try again later."))
+		return nil, apperrors.Wrap(err, "H2H_QUERY_ERROR", "error querying shared matches")
+	}
+
+	data := &HeadToHeadData{
+		AName: fmt.Sprintf("%s#%s", accountA.Name, accountA.Tag),
+		BName: fmt.Sprintf("%s#%s", accountB.Name, accountB.Tag),
+	}
+	for _, match := range shared {
+		group := &data.Against
+		if match.SameTeam {
+			group = &data.Together
+		}
+		group.Games++
+		if match.AWon {
+			group.AWins++
+		}
+		group.A.Kills += match.AKills
+		group.A.Deaths += match.ADeaths
+		group.A.Assists += match.AAssists
+		group.A.Score += match.AScore
+		group.B.Kills += match.BKills
+		group.B.Deaths += match.BDeaths
+		group.B.Assists += match.BAssists
+		group.B.Score += match.BScore
+	}
+	data.Recent = shared[:min(len(shared), 5)]
+
+	tracker.SendDone()
+	return data, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"yk-dc-bot/internal/analysis"
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/henrikapi"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/render"
+	"yk-dc-bot/internal/tracing"
+	"yk-dc-bot/internal/util"
+	"yk-dc-bot/internal/valorantapi"
+)
+
+// Heatmap renders where a player gets kills and dies on a map.
+type Heatmap struct {
+	players  *Players
+	valorant *valorantapi.ValorantAPI
+}
+
+func NewHeatmap(players *Players, valorant *valorantapi.ValorantAPI) *Heatmap {
+	return &Heatmap{players: players, valorant: valorant}
+}
+
+type HeatmapData struct {
+	AccountName string
+	AccountTag  string
+	Map         string
+	Matches     int
+	Kills       int
+	Deaths      int
+	Image       []byte
+}
+
+func (s *Heatmap) GetPlayerHeatmap(ctx context.Context, name, tag, region string, filter analysis.PositionFilter, tracker *util.ProgressTracker) (_ *HeatmapData, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetPlayerHeatmap", tracing.Attr("player", name+"#"+tag))
+	defer endSpan(span, &err)
+
+	time.Sleep(500 * time.Millisecond)
+
+	tracker.SendStep("progress.looking_up", i18n.Vars{"player": name + "#" + tag})
+	accountData, err := s.players.lookupAccount(ctx, name, tag, region, tracker)
+	if err != nil {
+		return nil, err
+	}
+
+	tracker.SendStep("progress.map_games", i18n.Vars{"map": strings.ToLower(filter.Map)})
+	matches, err := s.players.fetchMatches(ctx, accountData.Region, accountData.Puuid, 10)
+	if err != nil {
+		tracker.SendError(apperrors.Wrap(err, "MATCH_HISTORY_ERROR", "error fetching match history", "There was an error. Please try again later."))
+		return nil, apperrors.Wrap(err, "MATCH_HISTORY_ERROR", "error fetching match history")
+	}
+
+	positions := analysis.CollectPositions(accountData.Puuid, matches, filter)
+	if len(positions.Kills) == 0 && len(positions.Deaths) == 0 {
+		appErr := apperrors.New("HEATMAP_NO_DATA", "no kills or deaths matched the heatmap filter", fmt.Sprintf("No recent competitive kills or deaths found on %s with those filters", filter.Map))
+		tracker.SendError(appErr)
+		return nil, appErr
+	}
+
+	tracker.SendStep("progress.minimap")
+	mapData, err := s.valorant.GetMap(ctx, filter.Map)
+	if err != nil {
+		tracker.SendError(apperrors.Wrap(err, "MAP_DATA_ERROR", "error fetching map data", "There was an error. Please try again later."))
+		return nil, apperrors.Wrap(err, "MAP_DATA_ERROR", "error fetching map data")
+	}
+
+	minimap, err := s.valorant.GetMinimap(ctx, mapData)
+	if err != nil {
+		tracker.SendError(apperrors.Wrap(err, "MAP_DATA_ERROR", "error fetching minimap", "There was an error. Please try again later."))
+		return nil, apperrors.Wrap(err, "MAP_DATA_ERROR", "error fetching minimap")
+	}
+
+	project := func(locations []henrikapi.Location) []render.Point {
+		points := make([]render.Point, 0, len(locations))
+		for _, loc := range locations {
+			x, y := mapData.Project(float64(loc.X), float64(loc.Y))
+			points = append(points, render.Point{X: x, Y: y})
+		}
+		return points
+	}
+
+	img, err := render.Heatmap(minimap, []render.HeatmapLayer{
+		{Label: "kills", Color: render.ColorKills, Points: project(positions.Kills)},
+		{Label: "deaths", Color: render.ColorDeaths, Points: project(positions.Deaths)},
+	})
+	if err != nil {
+		tracker.SendError(apperrors.Wrap(err, "HEATMAP_RENDER_ERROR", "error rendering heatmap", "There was an error. Please try again later."))
+		return nil, apperrors.Wrap(err, "HEATMAP_RENDER_ERROR", "error rendering heatmap")
+	}
+
+	tracker.SendDone()
+	return &HeatmapData{
+		AccountName: accountData.Name,
+		AccountTag:  accountData.Tag,
+		Map:         mapData.DisplayName,
+		Matches:     positions.Matches,
+		Kills:       len(positions.Kills),
+		Deaths:      len(positions.Deaths),
+		Image:       img,
+	}, nil
+}
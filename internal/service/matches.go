@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"time"
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/tracing"
+	"yk-dc-bot/internal/util"
+)
+
+// Matches lists a player's recent matches.
+type Matches struct {
+	players *Players
+}
+
+func NewMatches(players *Players) *Matches {
+	return &Matches{players: players}
+}
+
+type MatchSummary struct {
+	MatchID    string
+	Map        string
+	Agent      string
+	StartedAt  time.Time
+	Won        bool
+	TeamScore  int
+	EnemyScore int
+	Kills      int
+	Deaths     int
+	Assists    int
+	PartySize  int
+}
+
+type MatchHistoryData struct {
+	AccountName string
+	AccountTag  string
+	Matches     []MatchSummary
+}
+
+func (s *Matches) GetPlayerMatches(ctx context.Context, name, tag, region string, matchCount int, tracker *util.ProgressTracker) (_ *MatchHistoryData, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetPlayerMatches", tracing.Attr("player", name+"#"+tag), tracing.Attr("match_count", matchCount))
+	defer endSpan(span, &err)
+
+	time.Sleep(500 * time.Millisecond)
+
+	tracker.SendStep("progress.looking_up", i18n.Vars{"player": name + "#" + tag})
+	accountData, err := s.players.lookupAccount(ctx, name, tag, region, tracker)
+	if err != nil {
+		return nil, err
+	}
+
+	tracker.SendStep("progress.recent_matches", i18n.Vars{"count": matchCount})
+	matches, err := s.players.fetchMatches(ctx, accountData.Region, accountData.Puuid, matchCount)
+	if err != nil {
+		tracker.SendError(apperrors.Wrap(err, "MATCH_HISTORY_ERROR", "error fetching match history", "There was an error. Please try again later."))
+		return nil, apperrors.Wrap(err, "MATCH_HISTORY_ERROR", "error fetching match history")
+	}
+
+	history := &MatchHistoryData{
+		AccountName: accountData.Name,
+		AccountTag:  accountData.Tag,
+	}
+	for idx := range matches {
+		match := &matches[idx]
+		player, ok := match.Player(accountData.Puuid)
+		if !ok {
+			continue
+		}
+
+		team := match.Team(player.Team)
+		history.Matches = append(history.Matches, MatchSummary{
+			MatchID:    match.Metadata.MatchID,
+			Map:        match.Metadata.Map,
+			Agent:      player.Character,
+			StartedAt:  time.Unix(match.Metadata.GameStart, 0),
+			Won:        team.HasWon,
+			TeamScore:  team.RoundsWon,
+			EnemyScore: team.RoundsLost,
+			Kills:      player.Stats.Kills,
+			Deaths:     player.Stats.Deaths,
+			Assists:    player.Stats.Assists,
+			PartySize:  len(match.PartyMembers(accountData.Puuid)) + 1,
+		})
+	}
+
+	tracker.SendDone()
+	return history, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/henrikapi"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/tracing"
+	"yk-dc-bot/internal/util"
+)
+
+// Rank looks up a player's competitive rank.
+type Rank struct {
+	henrik *henrikapi.HenrikDevAPI
+}
+
+func NewRank(henrik *henrikapi.HenrikDevAPI) *Rank {
+	return &Rank{henrik: henrik}
+}
+
+type RankData struct {
+	AccountName string
+	AccountTag  string
+	Rank        string
+	RR          int
+	LastGameRR  int
+	CardURL     string
+}
+
+func (s *Rank) GetPlayerRankData(ctx context.Context, name, tag, region string, tracker *util.ProgressTracker) (_ *RankData, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetPlayerRankData", tracing.Attr("player", name+"#"+tag))
+	defer endSpan(span, &err)
+
+	time.Sleep(500 * time.Millisecond)
+
+	tracker.SendStep("progress.rank", i18n.Vars{"player": name + "#" + tag})
+	accountData, err := lookupAccount(ctx, s.henrik, name, tag, region, tracker)
+	if err != nil {
+		return nil, err
+	}
+
+	time.Sleep(700 * time.Millisecond)
+
+	tracker.SendStep("progress.rank_more")
+	mmrData, err := s.henrik.GetMMRByPUUID(ctx, accountData.Region, accountData.Puuid)
+	if err != nil {
+		tracker.SendError(apperrors.Wrap(err, "MMR_DATA_ERROR", "error fetching rank data", "There was an error. Please try again later."))
+		return nil, apperrors.Wrap(err, "MMR_DATA_ERROR", "error fetching rank data")
+	}
+
+	time.Sleep(700 * time.Millisecond)
+
+	tracker.SendStep("progress.rank_card")
+	detailedAccountData, err := s.henrik.GetDetailedAccountByPUUID(ctx, accountData.Puuid)
+	if err != nil {
+		tracker.SendError(apperrors.Wrap(err, "DETAILED_ACCOUNT_DATA_ERROR", "error fetching detailed account data", "There was an error. Please try again later."))
+		return nil, apperrors.Wrap(err, "DETAILED_ACCOUNT_DATA_ERROR", "error fetching detailed account data")
+	}
+
+	rankData := &RankData{
+		AccountName: accountData.Name,
+		AccountTag:  accountData.Tag,
+		Rank:        mmrData.CurrentData.CurrentTierPatched,
+		RR:          mmrData.CurrentData.RankingInTier,
+		LastGameRR:  mmrData.CurrentData.MMRChangeToLastGame,
+	}
+
+	if detailedAccountData != nil {
+		rankData.CardURL = fmt.Sprintf("https://media.valorant-api.com/playercards/%s/smallart.png", detailedAccountData.Card.Small)
+	} else {
+		rankData.CardURL = mmrData.CurrentData.Images.Large
+	}
+
+	tracker.SendDone()
+	return rankData, nil
+}
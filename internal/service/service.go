@@ -1,52 +1,63 @@
 package service
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"strings"
-	"time"
 	"yk-dc-bot/internal/apperrors"
-	"yk-dc-bot/internal/config"
-	"yk-dc-bot/internal/database"
 	"yk-dc-bot/internal/henrikapi"
 	"yk-dc-bot/internal/logger"
-	"yk-dc-bot/internal/redisclient"
-	"yk-dc-bot/internal/trngg"
+	"yk-dc-bot/internal/matchstore"
+	"yk-dc-bot/internal/tracing"
 	"yk-dc-bot/internal/util"
 )
 
-type Service struct {
-	DB          *database.Database
-	Log         *logger.Logger
-	RedisClient *redisclient.Client
-	HenrikAPI   *henrikapi.HenrikDevAPI
-	TrackerAPI  *trngg.TrackerAPI
+// Players looks up accounts and their recent matches for the features that
+// start from a Riot ID. Fetched matches are recorded in the match store so
+// features like /h2h can look further back than the API allows.
+type Players struct {
+	henrik *henrikapi.HenrikDevAPI
+	store  *matchstore.Store
+	log    *logger.Logger
 }
 
-func NewService(db *database.Database, log *logger.Logger, redisClient *redisclient.Client, cfg *config.Config, henrikAPI *henrikapi.HenrikDevAPI) *Service {
-	return &Service{
-		DB:          db,
-		Log:         log,
-		RedisClient: redisClient,
-		HenrikAPI:   henrikAPI,
-		TrackerAPI:  trngg.NewTrackerAPI(cfg, redisClient, log),
+func NewPlayers(henrik *henrikapi.HenrikDevAPI, store *matchstore.Store, log *logger.Logger) *Players {
+	return &Players{henrik: henrik, store: store, log: log}
+}
+
+func (p *Players) lookupAccount(ctx context.Context, name, tag, region string, tracker *util.ProgressTracker) (*henrikapi.AccountData, error) {
+	return lookupAccount(ctx, p.henrik, name, tag, region, tracker)
+}
+
+func (p *Players) fetchMatches(ctx context.Context, region, puuid string, size int) ([]henrikapi.MatchData, error) {
+	matches, err := p.henrik.GetMatchesByPUUID(ctx, region, puuid, size)
+	if err != nil {
+		return nil, err
 	}
+
+	p.saveMatches(ctx, matches)
+	return matches, nil
 }
 
-type RankData struct {
-	AccountName string
-	AccountTag  string
-	Rank        string
-	RR          int
-	LastGameRR  int
-	CardURL     string
+// saveMatches records matches in the match store. A failure only costs
+// history, so it is logged rather than returned.
+func (p *Players) saveMatches(ctx context.Context, matches []henrikapi.MatchData) {
+	if err := p.store.SaveMatches(ctx, matches); err != nil {
+		logger.FromContext(ctx, p.log).Error("Failed to store matches", "error", err)
+	}
 }
 
-func (s *Service) GetPlayerRankData(name, tag string, tracker *util.ProgressTracker) (*RankData, error) {
-	time.Sleep(500 * time.Millisecond)
+// endSpan ends a service method's span, marking it failed when the method
+// returned an error.
+func endSpan(span *tracing.Span, err *error) {
+	span.SetError(*err)
+	span.End()
+}
 
-	tracker.SendUpdate(fmt.Sprintf("> right now, i'm fetching %s#%s's rank data", name, tag))
-	accountData, err := s.HenrikAPI.GetAccountByNameTag(name, tag)
+// lookupAccount finds the account behind a Riot ID. A non-empty region
+// replaces the account's own for the lookups that follow.
+func lookupAccount(ctx context.Context, henrik *henrikapi.HenrikDevAPI, name, tag, region string, tracker *util.ProgressTracker) (*henrikapi.AccountData, error) {
+	accountData, err := henrik.GetAccountByNameTag(ctx, name, tag)
 	if err != nil {
 		appErr := apperrors.Wrap(err, "ACCOUNT_DATA_ERROR", "error fetching account data", "There was an error. Please try again later.")
 		if errors.As(err, &appErr) && strings.Contains(appErr.Message, "not found") {
@@ -55,53 +66,10 @@ func (s *Service) GetPlayerRankData(name, tag string, tracker *util.ProgressTrac
 		tracker.SendError(appErr)
 		return nil, appErr
 	}
-
-	time.Sleep(700 * time.Millisecond)
-
-	tracker.SendUpdate("> alright... just some more things...")
-	mmrData, err := s.HenrikAPI.GetMMRByPUUID(accountData.Region, accountData.Puuid)
-	if err != nil {
-		tracker.SendError(apperrors.Wrap(err, "MMR_DATA_ERROR", "error fetching rank data", "There was an error. Please try again later."))
-		return nil, apperrors.Wrap(err, "MMR_DATA_ERROR", "error fetching rank data")
-	}
-
-	time.Sleep(700 * time.Millisecond)
-
-	tracker.SendUpdate("> oh, we can't forget about their card!")
-	detailedAccountData, err := s.HenrikAPI.GetDetailedAccountByPUUID(accountData.Puuid)
-	if err != nil {
-		tracker.SendError(apperrors.Wrap(err, "DETAILED_ACCOUNT_DATA_ERROR", "error fetching detailed account data", "There was an error. Please try again later."))
-		return nil, apperrors.Wrap(err, "DETAILED_ACCOUNT_DATA_ERROR", "error fetching detailed account data")
-	}
-
-	rankData := &RankData{
-		AccountName: accountData.Name,
-		AccountTag:  accountData.Tag,
-		Rank:        mmrData.CurrentData.CurrentTierPatched,
-		RR:          mmrData.CurrentData.RankingInTier,
-		LastGameRR:  mmrData.CurrentData.MMRChangeToLastGame,
+	if region != "" {
+		account := *accountData
+		account.Region = region
+		return &account, nil
 	}
-
-	if detailedAccountData != nil {
-		rankData.CardURL = fmt.Sprintf("https://media.valorant-api.com/playercards/%s/smallart.png", detailedAccountData.Card.Small)
-	} else {
-		rankData.CardURL = mmrData.CurrentData.Images.Large
-	}
-
-	tracker.SendDone()
-	return rankData, nil
-}
-
-func (s *Service) GetPlayerTrackerData(name, tag string, tracker *util.ProgressTracker) (*trngg.PlayerData, error) {
-	time.Sleep(500 * time.Millisecond)
-
-	tracker.SendUpdate(fmt.Sprintf("> right now, i'm fetching %s#%s's tracker data", name, tag))
-	playerData, err := s.TrackerAPI.GetPlayerTrackerData(name, tag)
-	if err != nil {
-		tracker.SendError(apperrors.Wrap(err, "TRACKER_DATA_ERROR", "error fetching tracker data", "There was an error. Please try again later."))
-		return nil, apperrors.Wrap(err, "TRACKER_DATA_ERROR", "error fetching tracker data")
-	}
-
-	tracker.SendDone()
-	return playerData, nil
+	return accountData, nil
 }
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/matchstore"
+	"yk-dc-bot/internal/tracing"
+	"yk-dc-bot/internal/util"
+)
+
+// Teammates finds who a player queues with.
+type Teammates struct {
+	players *Players
+	store   *matchstore.Store
+}
+
+func NewTeammates(players *Players, store *matchstore.Store) *Teammates {
+	return &Teammates{players: players, store: store}
+}
+
+type TeammateStats struct {
+	Names []string
+	Games int
+	Wins  int
+}
+
+type TeammatesData struct {
+	AccountName string
+	AccountTag  string
+	Matches     int
+	SoloGames   int
+	SoloWins    int
+	Teammates   []TeammateStats
+	Stacks      []TeammateStats
+}
+
+func (s *Teammates) GetPlayerTeammates(ctx context.Context, name, tag, region string, tracker *util.ProgressTracker) (_ *TeammatesData, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetPlayerTeammates", tracing.Attr("player", name+"#"+tag))
+	defer endSpan(span, &err)
+
+	time.Sleep(500 * time.Millisecond)
+
+	tracker.SendStep("progress.looking_up", i18n.Vars{"player": name + "#" + tag})
+	accountData, err := s.players.lookupAccount(ctx, name, tag, region, tracker)
+	if err != nil {
+		return nil, err
+	}
+
+	tracker.SendStep("progress.sync")
+	if _, err := s.players.fetchMatches(ctx, accountData.Region, accountData.Puuid, 10); err != nil {
+		tracker.SendError(apperrors.Wrap(err, "MATCH_HISTORY_ERROR", "error fetching match history", "There was an error. Please try again later."))
+		return nil, apperrors.Wrap(err, "MATCH_HISTORY_ERROR", "error fetching match history")
+	}
+
+	tracker.SendStep("progress.queue_partners")
+	partyMatches, err := s.store.PartyMatches(ctx, accountData.Puuid, 100)
+	if err != nil {
+		tracker.SendError(apperrors.Wrap(err, "TEAMMATES_QUERY_ERROR", "error querying party history", "There was an error. Please try again later."))
+		return nil, apperrors.Wrap(err, "TEAMMATES_QUERY_ERROR", "error querying party history")
+	}
+
+	data := &TeammatesData{
+		AccountName: accountData.Name,
+		AccountTag:  accountData.Tag,
+		Matches:     len(partyMatches),
+	}
+
+	teammates := make(map[string]*TeammateStats)
+	stacks := make(map[string]*TeammateStats)
+	for _, match := range partyMatches {
+		if len(match.Members) == 0 {
+			data.SoloGames++
+			if match.Won {
+				data.SoloWins++
+			}
+			continue
+		}
+
+		puuids := make([]string, 0, len(match.Members))
+		names := make([]string, 0, len(match.Members))
+		for _, member := range match.Members {
+			displayName := fmt.Sprintf("%s#%s", member.Name, member.Tag)
+
+			teammate, ok := teammates[member.Puuid]
+			if !ok {
+				teammate = &TeammateStats{Names: []string{displayName}}
+				teammates[member.Puuid] = teammate
+			}
+			teammate.Games++
+			if match.Won {
+				teammate.Wins++
+			}
+
+			puuids = append(puuids, member.Puuid)
+			names = append(names, displayName)
+		}
+
+		sort.Strings(puuids)
+		stackKey := strings.Join(puuids, ",")
+		stack, ok := stacks[stackKey]
+		if !ok {
+			sort.Strings(names)
+			stack = &TeammateStats{Names: names}
+			stacks[stackKey] = stack
+		}
+		stack.Games++
+		if match.Won {
+			stack.Wins++
+		}
+	}
+
+	data.Teammates = sortTeammateStats(teammates)
+	data.Stacks = sortTeammateStats(stacks)
+
+	tracker.SendDone()
+	return data, nil
+}
+
+func sortTeammateStats(byKey map[string]*TeammateStats) []TeammateStats {
+	sorted := make([]TeammateStats, 0, len(byKey))
+	for _, stats := range byKey {
+		sorted = append(sorted, *stats)
+	}
+	sort.Slice(sorted, func(a, b int) bool {
+		if sorted[a].Games != sorted[b].Games {
+			return sorted[a].Games > sorted[b].Games
+		}
+		return sorted[a].Wins > sorted[b].Wins
+	})
+	return sorted
+}
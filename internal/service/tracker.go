@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/tracing"
+	"yk-dc-bot/internal/trngg"
+	"yk-dc-bot/internal/util"
+)
+
+// Tracker looks up a player's tracker.gg profile.
+type Tracker struct {
+	trackerAPI *trngg.TrackerAPI
+}
+
+func NewTracker(trackerAPI *trngg.TrackerAPI) *Tracker {
+	return &Tracker{trackerAPI: trackerAPI}
+}
+
+func (s *Tracker) GetPlayerTrackerData(ctx context.Context, name, tag string, tracker *util.ProgressTracker) (_ *trngg.PlayerData, err error) {
+	ctx, span := tracing.Start(ctx, "service.GetPlayerTrackerData", tracing.Attr("player", name+"#"+tag))
+	defer endSpan(span, &err)
+
+	time.Sleep(500 * time.Millisecond)
+
+	tracker.SendStep("progress.tracker", i18n.Vars{"player": name + "#" + tag})
+	playerData, err := s.trackerAPI.GetPlayerTrackerData(ctx, name, tag)
+	if err != nil {
+		appErr := apperrors.Wrap(err, "TRACKER_DATA_ERROR", "error fetching tracker data", "There was an error. Please try again later.")
+		var fetchErr *apperrors.AppError
+		if errors.As(err, &fetchErr) && fetchErr.UserMessage != "" {
+			appErr.UserMessage = fetchErr.UserMessage
+		}
+		tracker.SendError(appErr)
+		return nil, appErr
+	}
+
+	tracker.SendDone()
+	return playerData, nil
+}
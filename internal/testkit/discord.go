@@ -0,0 +1,348 @@
+package testkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Call is a single request the bot made against the fake Discord API.
+type Call struct {
+	Method string
+	Path   string
+	Body   json.RawMessage
+	Files  []string
+}
+
+// Discord emulates the interaction callback and webhook endpoints the handlers
+// use, recording every response, edit and followup per interaction token.
+type Discord struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	calls     []Call
+	responses map[string][]discordgo.InteractionResponseType
+	originals map[string]map[string]json.RawMessage
+	followups map[string][]map[string]json.RawMessage
+	files     map[string][]string
+	nextID    int
+}
+
+func NewDiscord(t testing.TB) *Discord {
+	d := &Discord{
+		responses: make(map[string][]discordgo.InteractionResponseType),
+		originals: make(map[string]map[string]json.RawMessage),
+		followups: make(map[string][]map[string]json.RawMessage),
+		files:     make(map[string][]string),
+	}
+
+	api := "/api/v" + discordgo.APIVersion
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+api+"/interactions/{id}/{token}/callback", d.handleCallback)
+	mux.HandleFunc("GET "+api+"/webhooks/{app}/{token}/messages/{message}", d.handleGetMessage)
+	mux.HandleFunc("PATCH "+api+"/webhooks/{app}/{token}/messages/{message}", d.handleEditMessage)
+	mux.HandleFunc("DELETE "+api+"/webhooks/{app}/{token}/messages/{message}", d.handleDeleteMessage)
+	mux.HandleFunc("POST "+api+"/webhooks/{app}/{token}", d.handleFollowup)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		d.record(r, nil, nil)
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Unknown Endpoint", "code": 0})
+	})
+
+	d.server = httptest.NewServer(mux)
+	t.Cleanup(d.server.Close)
+	return d
+}
+
+// Session returns a session whose REST calls to discord.com are served by the
+// fake. Requests to any other host fail instead of reaching the network.
+func (d *Discord) Session() *discordgo.Session {
+	session, _ := discordgo.New("Bot testkit")
+	target, _ := url.Parse(d.server.URL)
+	session.Client = &http.Client{Transport: &rewriteTransport{target: target, hosts: []string{"discord.com"}}}
+	session.MaxRestRetries = 0
+	return session
+}
+
+func (d *Discord) Calls() []Call {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Call(nil), d.calls...)
+}
+
+// ResponseTypes lists the callback types sent for the interaction, in order.
+func (d *Discord) ResponseTypes(i *discordgo.InteractionCreate) []discordgo.InteractionResponseType {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]discordgo.InteractionResponseType(nil), d.responses[i.Token]...)
+}
+
+// Message is the interaction's original response with every edit applied, or
+// nil if the handler never responded.
+func (d *Discord) Message(i *discordgo.InteractionCreate) *discordgo.Message {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	original, ok := d.originals[i.Token]
+	if !ok {
+		return nil
+	}
+	return decodeMessage(original)
+}
+
+func (d *Discord) Followups(i *discordgo.InteractionCreate) []*discordgo.Message {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	messages := make([]*discordgo.Message, 0, len(d.followups[i.Token]))
+	for _, fields := range d.followups[i.Token] {
+		messages = append(messages, decodeMessage(fields))
+	}
+	return messages
+}
+
+// Files lists the attachment names uploaded for the interaction.
+func (d *Discord) Files(i *discordgo.InteractionCreate) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.files[i.Token]...)
+}
+
+func (d *Discord) Ephemeral(i *discordgo.InteractionCreate) bool {
+	message := d.Message(i)
+	return message != nil && message.Flags&discordgo.MessageFlagsEphemeral != 0
+}
+
+// FinalEmbed returns the first embed of the interaction's original response
+// as it stands after all edits, failing the test if there is none.
+func (d *Discord) FinalEmbed(t testing.TB, i *discordgo.InteractionCreate) *discordgo.MessageEmbed {
+	t.Helper()
+	message := d.Message(i)
+	if message == nil {
+		t.Fatalf("interaction %s was never responded to", i.ID)
+	}
+	if len(message.Embeds) == 0 {
+		t.Fatalf("interaction %s has no embeds, content %q", i.ID, message.Content)
+	}
+	return message.Embeds[0]
+}
+
+func (d *Discord) handleCallback(w http.ResponseWriter, r *http.Request) {
+	payload, files, err := readPayload(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error(), "code": 50035})
+		return
+	}
+	d.record(r, payload, files)
+
+	var response struct {
+		Type discordgo.InteractionResponseType `json:"type"`
+		Data map[string]json.RawMessage        `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &response); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error(), "code": 50035})
+		return
+	}
+
+	token := r.PathValue("token")
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.responses[token]) > 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": "Interaction has already been acknowledged.", "code": 40060})
+		return
+	}
+	d.responses[token] = append(d.responses[token], response.Type)
+	d.files[token] = append(d.files[token], files...)
+
+	original, ok := d.originals[token]
+	if !ok {
+		original = map[string]json.RawMessage{"id": d.newID()}
+		d.originals[token] = original
+	}
+	for key, value := range response.Data {
+		original[key] = value
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (d *Discord) handleGetMessage(w http.ResponseWriter, r *http.Request) {
+	d.record(r, nil, nil)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fields, ok := d.message(r.PathValue("token"), r.PathValue("message"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Unknown Message", "code": 10008})
+		return
+	}
+	writeJSON(w, http.StatusOK, fields)
+}
+
+func (d *Discord) handleEditMessage(w http.ResponseWriter, r *http.Request) {
+	payload, files, err := readPayload(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error(), "code": 50035})
+		return
+	}
+	d.record(r, payload, files)
+
+	var edit map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &edit); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error(), "code": 50035})
+		return
+	}
+
+	token := r.PathValue("token")
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	fields, ok := d.message(token, r.PathValue("message"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Unknown Message", "code": 10008})
+		return
+	}
+	for key, value := range edit {
+		fields[key] = value
+	}
+	d.files[token] = append(d.files[token], files...)
+	writeJSON(w, http.StatusOK, fields)
+}
+
+func (d *Discord) handleDeleteMessage(w http.ResponseWriter, r *http.Request) {
+	d.record(r, nil, nil)
+
+	token, messageID := r.PathValue("token"), r.PathValue("message")
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if messageID == "@original" {
+		delete(d.originals, token)
+	} else {
+		followups := d.followups[token][:0]
+		for _, fields := range d.followups[token] {
+			if string(fields["id"]) != strconv.Quote(messageID) {
+				followups = append(followups, fields)
+			}
+		}
+		d.followups[token] = followups
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (d *Discord) handleFollowup(w http.ResponseWriter, r *http.Request) {
+	payload, files, err := readPayload(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error(), "code": 50035})
+		return
+	}
+	d.record(r, payload, files)
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error(), "code": 50035})
+		return
+	}
+
+	token := r.PathValue("token")
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.originals[token]; !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Unknown Webhook", "code": 10015})
+		return
+	}
+	fields["id"] = d.newID()
+	d.followups[token] = append(d.followups[token], fields)
+	d.files[token] = append(d.files[token], files...)
+	writeJSON(w, http.StatusOK, fields)
+}
+
+// message looks up the original response or a followup; callers hold d.mu.
+func (d *Discord) message(token, messageID string) (map[string]json.RawMessage, bool) {
+	if messageID == "@original" {
+		fields, ok := d.originals[token]
+		return fields, ok
+	}
+	for _, fields := range d.followups[token] {
+		if string(fields["id"]) == strconv.Quote(messageID) {
+			return fields, true
+		}
+	}
+	return nil, false
+}
+
+func (d *Discord) newID() json.RawMessage {
+	d.nextID++
+	return json.RawMessage(strconv.Quote(strconv.Itoa(d.nextID)))
+}
+
+func (d *Discord) record(r *http.Request, body []byte, files []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, Call{Method: r.Method, Path: r.URL.Path, Body: body, Files: files})
+}
+
+// readPayload returns the JSON body of a request, unwrapping payload_json from
+// multipart uploads.
+func readPayload(r *http.Request) ([]byte, []string, error) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		body, err := io.ReadAll(r.Body)
+		return body, nil, err
+	}
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return nil, nil, err
+	}
+	var files []string
+	for _, headers := range r.MultipartForm.File {
+		for _, header := range headers {
+			files = append(files, header.Filename)
+		}
+	}
+	payload := r.MultipartForm.Value["payload_json"]
+	if len(payload) == 0 {
+		return nil, files, fmt.Errorf("multipart request without payload_json")
+	}
+	return []byte(payload[0]), files, nil
+}
+
+func decodeMessage(fields map[string]json.RawMessage) *discordgo.Message {
+	raw, _ := json.Marshal(fields)
+	var message discordgo.Message
+	_ = json.Unmarshal(raw, &message)
+	return &message
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// rewriteTransport sends requests for hosts to target and refuses everything
+// else, so a test can't accidentally reach the real service.
+type rewriteTransport struct {
+	target *url.URL
+	hosts  []string
+}
+
+func (t *rewriteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	for _, host := range t.hosts {
+		if r.URL.Hostname() == host {
+			rewritten := r.Clone(r.Context())
+			rewritten.URL.Scheme = t.target.Scheme
+			rewritten.URL.Host = t.target.Host
+			rewritten.Host = t.target.Host
+			return http.DefaultTransport.RoundTrip(rewritten)
+		}
+	}
+	return nil, fmt.Errorf("testkit: unexpected request to %s", r.URL)
+}
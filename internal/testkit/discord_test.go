@@ -0,0 +1,102 @@
+package testkit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestDiscordAppliesEditsToTheOriginal(t *testing.T) {
+	discord := NewDiscord(t)
+	session := discord.Session()
+	i := Command("rank").Build()
+
+	if err := session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"fetching", "done"} {
+		if _, err := session.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Embeds: &[]*discordgo.MessageEmbed{{Title: title}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := session.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{Content: "one more thing"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if types := discord.ResponseTypes(i); len(types) != 1 || types[0] != discordgo.InteractionResponseDeferredChannelMessageWithSource {
+		t.Errorf("response types = %v, want one deferred response", types)
+	}
+	AssertTitle(t, discord.FinalEmbed(t, i), "done")
+	if followups := discord.Followups(i); len(followups) != 1 || followups[0].Content != "one more thing" {
+		t.Errorf("followups = %+v, want the one sent", followups)
+	}
+	if calls := discord.Calls(); len(calls) != 4 {
+		t.Errorf("recorded %d calls, want 4", len(calls))
+	}
+}
+
+func TestDiscordKeepsInteractionsApart(t *testing.T) {
+	discord := NewDiscord(t)
+	session := discord.Session()
+	first, second := Command("rank").Build(), Command("rank").Build()
+
+	respond(t, session, first, &discordgo.InteractionResponseData{Content: "first"})
+
+	if discord.Message(second) != nil {
+		t.Error("second interaction has a response it never sent")
+	}
+	if message := discord.Message(first); message == nil || message.Content != "first" {
+		t.Errorf("first response = %+v", message)
+	}
+}
+
+func TestDiscordEphemeral(t *testing.T) {
+	discord := NewDiscord(t)
+	session := discord.Session()
+	public, private := Command("rank").Build(), Command("rank").Build()
+
+	respond(t, session, public, &discordgo.InteractionResponseData{Content: "everyone"})
+	respond(t, session, private, &discordgo.InteractionResponseData{Content: "just you", Flags: discordgo.MessageFlagsEphemeral})
+
+	if discord.Ephemeral(public) {
+		t.Error("public reply recorded as ephemeral")
+	}
+	discord.AssertReply(t, private, "just you", true)
+}
+
+func TestDiscordRecordsFiles(t *testing.T) {
+	discord := NewDiscord(t)
+	session := discord.Session()
+	i := Command("heatmap").Build()
+
+	respond(t, session, i, &discordgo.InteractionResponseData{
+		Files: []*discordgo.File{{Name: "heatmap.png", ContentType: "image/png", Reader: strings.NewReader("png")}},
+	})
+
+	if files := discord.Files(i); len(files) != 1 || files[0] != "heatmap.png" {
+		t.Errorf("files = %v, want heatmap.png", files)
+	}
+}
+
+func TestDiscordSessionStaysOffline(t *testing.T) {
+	session := NewDiscord(t).Session()
+
+	if _, err := session.Client.Get("https://example.com/"); err == nil {
+		t.Error("request to another host went through")
+	}
+}
+
+func respond(t *testing.T, session *discordgo.Session, i *discordgo.InteractionCreate, data *discordgo.InteractionResponseData) {
+	t.Helper()
+	if err := session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
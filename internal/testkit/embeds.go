@@ -0,0 +1,68 @@
+package testkit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Field returns the value of the embed field called name.
+func Field(embed *discordgo.MessageEmbed, name string) (string, bool) {
+	for _, field := range embed.Fields {
+		if field.Name == name {
+			return field.Value, true
+		}
+	}
+	return "", false
+}
+
+func AssertTitle(t testing.TB, embed *discordgo.MessageEmbed, want string) {
+	t.Helper()
+	if embed.Title != want {
+		t.Errorf("embed title = %q, want %q", embed.Title, want)
+	}
+}
+
+// AssertField checks that the embed has a field called name whose value
+// contains want.
+func AssertField(t testing.TB, embed *discordgo.MessageEmbed, name, want string) {
+	t.Helper()
+	value, ok := Field(embed, name)
+	if !ok {
+		t.Errorf("embed %q has no field %q", embed.Title, name)
+		return
+	}
+	if !strings.Contains(value, want) {
+		t.Errorf("embed field %q = %q, want it to contain %q", name, value, want)
+	}
+}
+
+// AssertError checks that the interaction ended on an error embed whose
+// description contains want.
+func (d *Discord) AssertError(t testing.TB, i *discordgo.InteractionCreate, want string) {
+	t.Helper()
+	embed := d.FinalEmbed(t, i)
+	if embed.Title != "Error" {
+		t.Fatalf("final embed is %q, want an error embed", embed.Title)
+	}
+	if !strings.Contains(embed.Description, want) {
+		t.Errorf("error embed = %q, want it to contain %q", embed.Description, want)
+	}
+}
+
+// AssertReply checks that the interaction got a direct reply containing want,
+// and whether it was ephemeral.
+func (d *Discord) AssertReply(t testing.TB, i *discordgo.InteractionCreate, want string, ephemeral bool) {
+	t.Helper()
+	message := d.Message(i)
+	if message == nil {
+		t.Fatalf("interaction %s was never responded to", i.ID)
+	}
+	if !strings.Contains(message.Content, want) {
+		t.Errorf("reply = %q, want it to contain %q", message.Content, want)
+	}
+	if d.Ephemeral(i) != ephemeral {
+		t.Errorf("reply ephemeral = %v, want %v", !ephemeral, ephemeral)
+	}
+}
@@ -0,0 +1,51 @@
+package testkit
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// failures records what an assertion reported instead of failing the test.
+type failures struct {
+	testing.TB
+	errors []string
+}
+
+func (f *failures) Helper() {}
+
+func (f *failures) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertField(t *testing.T) {
+	embed := &discordgo.MessageEmbed{Title: "tester#fake", Fields: []*discordgo.MessageEmbedField{
+		{Name: "rank", Value: "> Diamond 1"},
+	}}
+
+	if value, ok := Field(embed, "rank"); !ok || value != "> Diamond 1" {
+		t.Errorf("Field = %q, %v", value, ok)
+	}
+
+	for _, tc := range []struct {
+		name, want string
+		fails      bool
+	}{
+		{"rank", "Diamond 1", false},
+		{"rank", "Gold 2", true},
+		{"ranked rating", "42/100", true},
+	} {
+		f := &failures{TB: t}
+		AssertField(f, embed, tc.name, tc.want)
+		if failed := len(f.errors) > 0; failed != tc.fails {
+			t.Errorf("AssertField(%q, %q) failed = %v, want %v (%v)", tc.name, tc.want, failed, tc.fails, f.errors)
+		}
+	}
+
+	f := &failures{TB: t}
+	AssertTitle(f, embed, "someone#else")
+	if len(f.errors) != 1 {
+		t.Errorf("AssertTitle with the wrong title reported %v", f.errors)
+	}
+}
@@ -0,0 +1,53 @@
+package testkit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"yk-dc-bot/internal/apperrors"
+)
+
+func TestFixturesReplayRecordedResponses(t *testing.T) {
+	body := Fixture(t, HenrikBaseURL+"/v2/account/tester/fake")
+
+	var response struct {
+		Status int `json:"status"`
+		Data   struct {
+			Puuid string `json:"puuid"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Status != http.StatusOK || response.Data.Puuid != "fake-puuid-tester" {
+		t.Errorf("replayed %+v, want tester's account", response)
+	}
+}
+
+func TestFixturesMissingRecording(t *testing.T) {
+	_, err := Fixtures(t).Load(http.MethodGet, HenrikBaseURL+"/v2/account/unrecorded/fake")
+
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Code != "FIXTURE_MISSING" {
+		t.Errorf("err = %v, want FIXTURE_MISSING", err)
+	}
+}
+
+func TestReplayConfigStaysOffline(t *testing.T) {
+	client := &http.Client{Transport: Fixtures(t).Transport(nil)}
+
+	resp, err := client.Get(TrackerBaseURL + "/tester%23fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want the recorded 200", resp.StatusCode)
+	}
+
+	if _, err := client.Get("https://example.com/"); err == nil {
+		t.Error("request without a fixture went through")
+	}
+}
@@ -0,0 +1,228 @@
+package testkit
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"yk-dc-bot/internal/bot"
+	"yk-dc-bot/internal/commands"
+	"yk-dc-bot/internal/components"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/guildsettings"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/interaction"
+	"yk-dc-bot/internal/logger"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	AppID     = "100000000000000001"
+	GuildID   = "100000000000000002"
+	UserID    = "100000000000000003"
+	ChannelID = "100000000000000004"
+)
+
+var interactionCount atomic.Int64
+
+// Interaction builds a synthetic InteractionCreate. By default it comes from
+// UserID in ChannelID of GuildID.
+type Interaction struct {
+	i       *discordgo.InteractionCreate
+	data    *discordgo.ApplicationCommandInteractionData
+	options *[]*discordgo.ApplicationCommandInteractionDataOption
+}
+
+func Command(name string) *Interaction {
+	b := newInteraction(discordgo.InteractionApplicationCommand)
+	b.data = &discordgo.ApplicationCommandInteractionData{
+		ID:          snowflake(time.Now()),
+		Name:        name,
+		CommandType: discordgo.ChatApplicationCommand,
+	}
+	b.options = &b.data.Options
+	return b
+}
+
+func Autocomplete(name string) *Interaction {
+	b := Command(name)
+	b.i.Type = discordgo.InteractionApplicationCommandAutocomplete
+	return b
+}
+
+func Component(customID string) *Interaction {
+	b := newInteraction(discordgo.InteractionMessageComponent)
+	b.i.Data = discordgo.MessageComponentInteractionData{
+		CustomID:      customID,
+		ComponentType: discordgo.ButtonComponent,
+	}
+	return b
+}
+
+func newInteraction(kind discordgo.InteractionType) *Interaction {
+	n := interactionCount.Add(1)
+	return &Interaction{i: &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:        snowflake(time.Now()),
+		AppID:     AppID,
+		Type:      kind,
+		GuildID:   GuildID,
+		ChannelID: ChannelID,
+		Token:     "testkit-token-" + strconv.FormatInt(n, 10),
+		Member: &discordgo.Member{
+			User:        &discordgo.User{ID: UserID, Username: "tester"},
+			Permissions: discordgo.PermissionViewChannel | discordgo.PermissionSendMessages,
+		},
+		Locale: discordgo.EnglishUS,
+	}}}
+}
+
+// Subcommand nests the following options under a subcommand, or under a
+// group and subcommand when given "group sub".
+func (b *Interaction) Subcommand(path string) *Interaction {
+	for idx, name := range strings.Fields(path) {
+		kind := discordgo.ApplicationCommandOptionSubCommand
+		if idx == 0 && len(strings.Fields(path)) > 1 {
+			kind = discordgo.ApplicationCommandOptionSubCommandGroup
+		}
+		option := &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: kind}
+		*b.options = append(*b.options, option)
+		b.options = &option.Options
+	}
+	return b
+}
+
+func (b *Interaction) String(name, value string) *Interaction {
+	return b.option(name, discordgo.ApplicationCommandOptionString, value, false)
+}
+
+func (b *Interaction) Int(name string, value int64) *Interaction {
+	// JSON numbers decode to float64, which is what IntValue expects
+	return b.option(name, discordgo.ApplicationCommandOptionInteger, float64(value), false)
+}
+
+func (b *Interaction) Bool(name string, value bool) *Interaction {
+	return b.option(name, discordgo.ApplicationCommandOptionBoolean, value, false)
+}
+
+// Focused adds the option being typed in during autocomplete.
+func (b *Interaction) Focused(name, value string) *Interaction {
+	return b.option(name, discordgo.ApplicationCommandOptionString, value, true)
+}
+
+func (b *Interaction) option(name string, kind discordgo.ApplicationCommandOptionType, value interface{}, focused bool) *Interaction {
+	*b.options = append(*b.options, &discordgo.ApplicationCommandInteractionDataOption{
+		Name:    name,
+		Type:    kind,
+		Value:   value,
+		Focused: focused,
+	})
+	return b
+}
+
+// By sets the invoking member and their roles.
+func (b *Interaction) By(userID string, roles ...string) *Interaction {
+	b.i.Member.User.ID = userID
+	b.i.Member.Roles = roles
+	return b
+}
+
+func (b *Interaction) Permissions(permissions int64) *Interaction {
+	b.i.Member.Permissions = permissions
+	return b
+}
+
+func (b *Interaction) InGuild(guildID, channelID string) *Interaction {
+	b.i.GuildID = guildID
+	b.i.ChannelID = channelID
+	return b
+}
+
+// InDM makes the interaction come from a direct message with userID.
+func (b *Interaction) InDM(userID string) *Interaction {
+	b.i.GuildID = ""
+	b.i.Member = nil
+	b.i.User = &discordgo.User{ID: userID, Username: "tester"}
+	return b
+}
+
+func (b *Interaction) Locale(locale discordgo.Locale) *Interaction {
+	b.i.Locale = locale
+	return b
+}
+
+func (b *Interaction) Build() *discordgo.InteractionCreate {
+	if b.data != nil {
+		b.i.Data = *b.data
+	}
+	return b.i
+}
+
+// Bot is what a handler test needs to run interactions against the fakes.
+type Bot struct {
+	Discord  *Discord
+	Session  *discordgo.Session
+	Config   *config.Config
+	Catalog  *i18n.Catalog
+	Log      *logger.Logger
+	Commands *commands.Registry
+	Router   *components.Router
+	// Guilds backs the guild settings middleware; nil means every guild has
+	// the defaults.
+	Guilds *guildsettings.Store
+
+	bot *bot.DiscordBot
+}
+
+// NewBot wires a session against a fresh fake Discord. Commands, Router and
+// Guilds may be left nil; set them before the first Run.
+func NewBot(t testing.TB, cfg *config.Config) *Bot {
+	discord := NewDiscord(t)
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	catalog, err := i18n.NewCatalog()
+	if err != nil {
+		t.Fatalf("loading the i18n catalog: %v", err)
+	}
+	return &Bot{
+		Discord: discord,
+		Session: discord.Session(),
+		Config:  cfg,
+		Catalog: catalog,
+		Log:     logger.NewLogger(),
+	}
+}
+
+func (b *Bot) Ctx(i *discordgo.InteractionCreate) *interaction.Ctx {
+	return interaction.New(b.Session, i, b.Catalog, b.Log, b.Config)
+}
+
+// Run hands the interaction to the real bot.DiscordBot, with its global
+// middlewares and in-flight tracking, and waits for the handler to return.
+// The bot is built on the first Run from Commands, Router and Guilds.
+func (b *Bot) Run(t testing.TB, i *discordgo.InteractionCreate) {
+	t.Helper()
+	if b.bot == nil {
+		if b.Commands == nil {
+			b.Commands = commands.NewRegistry(commands.RegistryParams{Catalog: b.Catalog})
+		}
+		if b.Router == nil {
+			b.Router = components.NewRouter(components.RouterParams{})
+		}
+		discordBot, err := bot.NewDiscordBot(b.Config, b.Log, b.Catalog, b.Commands, b.Router, b.Guilds, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("creating the bot: %v", err)
+		}
+		b.bot = discordBot
+	}
+	b.bot.Handle(b.Session, i)
+}
+
+// snowflake encodes at as a Discord ID so interaction deadlines work.
+func snowflake(at time.Time) string {
+	const discordEpoch = 1420070400000
+	return strconv.FormatInt((at.UnixMilli()-discordEpoch)<<22|interactionCount.Load()&0xfff, 10)
+}
@@ -0,0 +1,91 @@
+package testkit
+
+import (
+	"testing"
+	"time"
+
+	"yk-dc-bot/internal/interaction"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestCommandNestsSubcommandOptions(t *testing.T) {
+	i := Command("settings").Subcommand("set").String("key", "language").String("value", "de").Build()
+
+	segments, options := interaction.Walk(i.ApplicationCommandData())
+	if len(segments) != 2 || segments[0] != "settings" || segments[1] != "set" {
+		t.Errorf("path = %v, want settings set", segments)
+	}
+	if len(options) != 2 || options[0].StringValue() != "language" || options[1].StringValue() != "de" {
+		t.Errorf("options = %+v, want key and value", options)
+	}
+}
+
+func TestCommandSubcommandGroup(t *testing.T) {
+	i := Command("admin").Subcommand("cache clear").Bool("all", true).Build()
+
+	data := i.ApplicationCommandData()
+	group := data.Options[0]
+	if group.Type != discordgo.ApplicationCommandOptionSubCommandGroup || group.Name != "cache" {
+		t.Fatalf("first option = %+v, want the cache group", group)
+	}
+	if sub := group.Options[0]; sub.Type != discordgo.ApplicationCommandOptionSubCommand || sub.Name != "clear" || !sub.Options[0].BoolValue() {
+		t.Errorf("subcommand = %+v, want clear with all=true", sub)
+	}
+}
+
+func TestIntOptionsReadBack(t *testing.T) {
+	ctx := NewBot(t, nil).Ctx(Command("analyze").Int("matches", 7).Build())
+	defer ctx.Close()
+
+	if got := ctx.IntOr("matches", 5); got != 7 {
+		t.Errorf("matches = %d, want 7", got)
+	}
+}
+
+func TestInteractionDefaultsAndOverrides(t *testing.T) {
+	i := Command("rank").Build()
+	if i.GuildID != GuildID || i.ChannelID != ChannelID || i.Member.User.ID != UserID || i.Locale != discordgo.EnglishUS {
+		t.Errorf("defaults = guild %s, channel %s, user %s, locale %s", i.GuildID, i.ChannelID, i.Member.User.ID, i.Locale)
+	}
+
+	dm := Command("rank").InDM("200000000000000009").Locale(discordgo.German).Build()
+	if dm.GuildID != "" || dm.Member != nil || dm.User.ID != "200000000000000009" || dm.Locale != discordgo.German {
+		t.Errorf("dm interaction = %+v", dm.Interaction)
+	}
+
+	admin := Command("settings").By("200000000000000010", "role").Permissions(discordgo.PermissionManageServer).Build()
+	if admin.Member.User.ID != "200000000000000010" || admin.Member.Roles[0] != "role" || admin.Member.Permissions != discordgo.PermissionManageServer {
+		t.Errorf("member = %+v", admin.Member)
+	}
+}
+
+func TestInteractionIDsCarryTheCurrentTime(t *testing.T) {
+	first, second := Command("rank").Build(), Command("rank").Build()
+
+	created, err := discordgo.SnowflakeTimestamp(first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if since := time.Since(created); since < 0 || since > time.Minute {
+		t.Errorf("interaction created %v ago, want just now", since)
+	}
+	if first.Token == second.Token {
+		t.Error("two interactions share a token")
+	}
+}
+
+func TestComponentAndAutocomplete(t *testing.T) {
+	component := Component("rank:refresh:tester:fake").Build()
+	if component.Type != discordgo.InteractionMessageComponent || component.MessageComponentData().CustomID != "rank:refresh:tester:fake" {
+		t.Errorf("component = %+v", component.Interaction)
+	}
+
+	autocomplete := Autocomplete("rank").Focused("username", "tes").Build()
+	if autocomplete.Type != discordgo.InteractionApplicationCommandAutocomplete {
+		t.Errorf("type = %v, want autocomplete", autocomplete.Type)
+	}
+	if option := autocomplete.ApplicationCommandData().Options[0]; !option.Focused || option.StringValue() != "tes" {
+		t.Errorf("focused option = %+v", option)
+	}
+}
@@ -0,0 +1,237 @@
+package testkit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/redisclient"
+)
+
+// Redis is an in-memory stand-in speaking just enough RESP for the bot's
+// caching. It doesn't run Lua, so cooldown checks fail open against it.
+type Redis struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+}
+
+func NewRedis(t testing.TB) *Redis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("starting fake redis: %v", err)
+	}
+
+	r := &Redis{
+		listener: listener,
+		values:   make(map[string]string),
+		expires:  make(map[string]time.Time),
+	}
+	go r.serve()
+	t.Cleanup(func() { listener.Close() })
+	return r
+}
+
+// Client connects a redisclient.Client to the fake.
+func (r *Redis) Client(t testing.TB) *redisclient.Client {
+	t.Helper()
+	host, port, _ := net.SplitHostPort(r.listener.Addr().String())
+	client, err := redisclient.NewRedisClient(&config.Config{
+		Redis: config.RedisConfig{Host: host, Port: port},
+	}, logger.NewLogger(), nil)
+	if err != nil {
+		t.Fatalf("connecting to fake redis: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func (r *Redis) Get(key string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.get(key)
+}
+
+func (r *Redis) Set(key, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[key] = value
+	delete(r.expires, key)
+}
+
+func (r *Redis) Keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := make([]string, 0, len(r.values))
+	for key := range r.values {
+		if _, ok := r.get(key); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (r *Redis) serve() {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			return
+		}
+		go r.handle(conn)
+	}
+}
+
+func (r *Redis) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		r.execute(writer, args)
+		if writer.Flush() != nil {
+			return
+		}
+	}
+}
+
+var redisArity = map[string]int{"GET": 2, "SET": 3, "DEL": 2, "INCR": 2, "PEXPIRE": 3, "PTTL": 2}
+
+func (r *Redis) execute(w *bufio.Writer, args []string) {
+	if len(args) == 0 {
+		writeError(w, "ERR empty command")
+		return
+	}
+
+	command := strings.ToUpper(args[0])
+	if arity, ok := redisArity[command]; ok && len(args) < arity {
+		writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", args[0]))
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch command {
+	case "PING":
+		fmt.Fprint(w, "+PONG\r\n")
+	case "GET":
+		if value, ok := r.get(args[1]); ok {
+			writeBulk(w, value)
+		} else {
+			fmt.Fprint(w, "$-1\r\n")
+		}
+	case "SET":
+		r.values[args[1]] = args[2]
+		delete(r.expires, args[1])
+		for idx := 3; idx+1 < len(args); idx += 2 {
+			amount, _ := strconv.ParseInt(args[idx+1], 10, 64)
+			switch strings.ToUpper(args[idx]) {
+			case "EX":
+				r.expires[args[1]] = time.Now().Add(time.Duration(amount) * time.Second)
+			case "PX":
+				r.expires[args[1]] = time.Now().Add(time.Duration(amount) * time.Millisecond)
+			}
+		}
+		fmt.Fprint(w, "+OK\r\n")
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := r.get(key); ok {
+				deleted++
+			}
+			delete(r.values, key)
+			delete(r.expires, key)
+		}
+		fmt.Fprintf(w, ":%d\r\n", deleted)
+	case "INCR":
+		value, _ := r.get(args[1])
+		count, _ := strconv.ParseInt(value, 10, 64)
+		count++
+		r.values[args[1]] = strconv.FormatInt(count, 10)
+		fmt.Fprintf(w, ":%d\r\n", count)
+	case "PEXPIRE":
+		if _, ok := r.get(args[1]); !ok {
+			fmt.Fprint(w, ":0\r\n")
+			return
+		}
+		ms, _ := strconv.ParseInt(args[2], 10, 64)
+		r.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		fmt.Fprint(w, ":1\r\n")
+	case "PTTL":
+		if _, ok := r.get(args[1]); !ok {
+			fmt.Fprint(w, ":-2\r\n")
+			return
+		}
+		expiry, ok := r.expires[args[1]]
+		if !ok {
+			fmt.Fprint(w, ":-1\r\n")
+			return
+		}
+		fmt.Fprintf(w, ":%d\r\n", time.Until(expiry).Milliseconds())
+	default:
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", args[0]))
+	}
+}
+
+// get drops expired keys on access; callers hold r.mu.
+func (r *Redis) get(key string) (string, bool) {
+	if expiry, ok := r.expires[key]; ok && time.Now().After(expiry) {
+		delete(r.values, key)
+		delete(r.expires, key)
+	}
+	value, ok := r.values[key]
+	return value, ok
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, count)
+	for idx := 0; idx < count; idx++ {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+func writeBulk(w *bufio.Writer, value string) {
+	fmt.Fprintf(w, "$%d\r\n%s\r\n", len(value), value)
+}
+
+func writeError(w *bufio.Writer, message string) {
+	fmt.Fprintf(w, "-%s\r\n", message)
+}
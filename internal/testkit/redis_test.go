@@ -0,0 +1,52 @@
+package testkit
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestRedisServesTheClient(t *testing.T) {
+	redis := NewRedis(t)
+	client := redis.Client(t)
+	ctx := context.Background()
+
+	if err := client.Set(ctx, "account:tester:fake", "cached", 0); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := client.Get(ctx, "account:tester:fake"); err != nil || value != "cached" {
+		t.Errorf("Get = %q, %v, want the value set", value, err)
+	}
+	if value, ok := redis.Get("account:tester:fake"); !ok || value != "cached" {
+		t.Errorf("fake holds %q, %v", value, ok)
+	}
+
+	if err := client.Del(ctx, "account:tester:fake"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(ctx, "account:tester:fake"); err == nil {
+		t.Error("Get found a deleted key")
+	}
+}
+
+func TestRedisExpiresKeys(t *testing.T) {
+	redis := NewRedis(t)
+	client := redis.Client(t)
+	ctx := context.Background()
+
+	if err := client.Set(ctx, "mmr:eu:fake-puuid-tester", "soon gone", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	redis.Set("tracker:tester:fake", "kept")
+	time.Sleep(50 * time.Millisecond)
+
+	if _, ok := redis.Get("mmr:eu:fake-puuid-tester"); ok {
+		t.Error("key outlived its TTL")
+	}
+	keys := redis.Keys()
+	sort.Strings(keys)
+	if len(keys) != 1 || keys[0] != "tracker:tester:fake" {
+		t.Errorf("keys = %v, want only the one without a TTL", keys)
+	}
+}
@@ -0,0 +1,28 @@
+package testkit
+
+import (
+	"testing"
+
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/schema"
+)
+
+// StrictSchemas returns a detector that turns drift in any required field
+// into an error, for API clients under test.
+func StrictSchemas() *schema.Detector {
+	return schema.NewDetector(&config.Config{SchemaStrict: true}, nil, logger.NewLogger())
+}
+
+// AssertSchema fails the test when body is missing a required field of s or
+// has one with the wrong type, e.g. to check recorded fixtures still match.
+func AssertSchema(t testing.TB, s *schema.Schema, body []byte) {
+	t.Helper()
+	for _, problem := range s.Check(body) {
+		if problem.Required {
+			t.Errorf("%s: %s", s.Name, problem)
+		} else {
+			t.Logf("%s: %s", s.Name, problem)
+		}
+	}
+}
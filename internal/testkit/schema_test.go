@@ -0,0 +1,64 @@
+package testkit
+
+import (
+	"testing"
+
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/henrikapi"
+	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/schema"
+	"yk-dc-bot/internal/trngg"
+)
+
+const driftedAccount = HenrikBaseURL + "/v2/account/drifted/fake"
+
+func TestFixturesMatchSchemas(t *testing.T) {
+	for _, tc := range []struct {
+		schema *schema.Schema
+		url    string
+	}{
+		{henrikapi.AccountSchema, HenrikBaseURL + "/v2/account/tester/fake"},
+		{henrikapi.DetailedAccountSchema, HenrikBaseURL + "/v2/by-puuid/account/fake-puuid-tester"},
+		{henrikapi.MMRSchema, HenrikBaseURL + "/v2/by-puuid/mmr/eu/fake-puuid-tester"},
+		{henrikapi.MMRSchema, HenrikBaseURL + "/v2/by-puuid/mmr/na/fake-puuid-tester"},
+		{trngg.ProfileSchema, TrackerBaseURL + "/tester%23fake"},
+	} {
+		t.Run(tc.schema.Name, func(t *testing.T) {
+			body := Fixture(t, tc.url)
+			AssertSchema(t, tc.schema, body)
+			if err := StrictSchemas().Validate(tc.schema, body); err != nil {
+				t.Errorf("strict detector rejected the fixture: %v", err)
+			}
+		})
+	}
+}
+
+func TestAssertSchemaReportsDrift(t *testing.T) {
+	f := &failures{TB: t}
+	AssertSchema(f, henrikapi.AccountSchema, Fixture(t, driftedAccount))
+
+	if len(f.errors) != 1 || f.errors[0] != "henrikdev.account: data.region missing" {
+		t.Errorf("AssertSchema reported %q, want the missing region", f.errors)
+	}
+}
+
+func TestDriftFailsOnlyInStrictMode(t *testing.T) {
+	body := Fixture(t, driftedAccount)
+
+	strict := StrictSchemas()
+	if err := strict.Validate(henrikapi.AccountSchema, body); err == nil {
+		t.Error("strict detector accepted a payload without data.region")
+	}
+
+	normal := schema.NewDetector(&config.Config{}, nil, logger.NewLogger())
+	if err := normal.Validate(henrikapi.AccountSchema, body); err != nil {
+		t.Errorf("normal detector failed on drift: %v", err)
+	}
+
+	// both logged and counted the drift
+	for name, detector := range map[string]*schema.Detector{"strict": strict, "normal": normal} {
+		if count := detector.Counts()[henrikapi.AccountSchema.Name]; count != 1 {
+			t.Errorf("%s detector counted %d drifted responses, want 1", name, count)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	mathrand "math/rand"
+	"sync"
+	"time"
+
+	"yk-dc-bot/internal/apperrors"
+)
+
+type TraceID [16]byte
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+type SpanID [8]byte
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id SpanID) IsZero() bool {
+	return id == SpanID{}
+}
+
+// Kind follows the OTLP span kinds the bot uses.
+type Kind int
+
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindServer:
+		return "server"
+	case KindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attr makes an attribute. Values should be strings, bools, ints or floats;
+// anything else is exported as its fmt representation.
+func Attr(key string, value any) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is one timed operation within a trace. Every method is safe to call
+// on a nil Span, so instrumented code never has to check.
+type Span struct {
+	Name     string
+	Kind     Kind
+	TraceID  TraceID
+	SpanID   SpanID
+	ParentID SpanID
+	Start    time.Time
+
+	sampled bool
+
+	mu         sync.Mutex
+	end        time.Time
+	attributes []Attribute
+	err        string
+	ended      bool
+}
+
+type contextKey struct{}
+
+// FromContext returns the span carried by ctx, or nil.
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(contextKey{}).(*Span)
+	return span
+}
+
+// Start begins an internal span as a child of the span in ctx, or as the
+// root of a new trace.
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	return start(ctx, KindInternal, name, attrs)
+}
+
+// StartServer begins a span for work the bot was asked to do, such as an
+// interaction.
+func StartServer(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	return start(ctx, KindServer, name, attrs)
+}
+
+// StartClient begins a span for a call to another service.
+func StartClient(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	return start(ctx, KindClient, name, attrs)
+}
+
+func start(ctx context.Context, kind Kind, name string, attrs []Attribute) (context.Context, *Span) {
+	span := &Span{Name: name, Kind: kind, SpanID: newSpanID(), Start: time.Now(), attributes: attrs}
+	if parent := FromContext(ctx); parent != nil {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+		span.sampled = parent.sampled
+	} else {
+		span.TraceID = newTraceID()
+		span.sampled = sample()
+	}
+	return context.WithValue(ctx, contextKey{}, span), span
+}
+
+// SetAttributes adds attributes, replacing any with the same key.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+next:
+	for _, attr := range attrs {
+		for i := range s.attributes {
+			if s.attributes[i].Key == attr.Key {
+				s.attributes[i].Value = attr.Value
+				continue next
+			}
+		}
+		s.attributes = append(s.attributes, attr)
+	}
+}
+
+// SetError marks the span as failed. A nil err does nothing; app errors also
+// record their code.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		s.SetAttributes(Attr("error.code", appErr.Code))
+	}
+	s.mu.Lock()
+	s.err = err.Error()
+	s.mu.Unlock()
+}
+
+// End finishes the span and hands it to the exporter. Only the first call
+// counts.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	if s.sampled {
+		if t := active.Load(); t != nil {
+			t.enqueue(s)
+		}
+	}
+}
+
+// snapshot is a span's finished state, read by exporters.
+type snapshot struct {
+	*Span
+	end        time.Time
+	attributes []Attribute
+	err        string
+}
+
+func (s *Span) snapshot() snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return snapshot{Span: s, end: s.end, attributes: append([]Attribute(nil), s.attributes...), err: s.err}
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	random(id[:])
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	random(id[:])
+	return id
+}
+
+// random fills b from crypto/rand, falling back to math/rand so an ID is
+// always produced.
+func random(b []byte) {
+	if _, err := rand.Read(b); err == nil {
+		return
+	}
+	for i := 0; i < len(b); i += 8 {
+		var chunk [8]byte
+		binary.LittleEndian.PutUint64(chunk[:], mathrand.Uint64())
+		copy(b[i:], chunk[:])
+	}
+}
+
+func sample() bool {
+	t := active.Load()
+	if t == nil {
+		return false
+	}
+	return t.sampleRate >= 1 || mathrand.Float64() < t.sampleRate
+}
@@ -0,0 +1,134 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/logger"
+)
+
+const (
+	// queueSize bounds how many ended spans can wait for export; past it new
+	// ones are dropped rather than slowing the handlers down.
+	queueSize = 2048
+	batchSize = 256
+	// flushInterval is how long a span may wait for a full batch.
+	flushInterval = 5 * time.Second
+	exportTimeout = 10 * time.Second
+)
+
+type exporter interface {
+	export(ctx context.Context, spans []snapshot) error
+}
+
+// active is the started tracer ended spans are handed to. While it is nil,
+// spans still get IDs, so correlation IDs work, but nothing is exported.
+var active atomic.Pointer[Tracer]
+
+// Tracer batches ended spans and sends them to the configured exporter.
+type Tracer struct {
+	log        *logger.Logger
+	exporter   exporter
+	sampleRate float64
+
+	spans   chan *Span
+	dropped atomic.Int64
+
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+func NewTracer(cfg *config.Config, log *logger.Logger) *Tracer {
+	t := &Tracer{log: log, sampleRate: cfg.Tracing.SampleRate, spans: make(chan *Span, queueSize)}
+	switch cfg.Tracing.Exporter {
+	case "stdout":
+		t.exporter = newStdoutExporter()
+	case "otlp":
+		t.exporter = newOTLPExporter(cfg.Tracing.Endpoint, cfg.Tracing.ServiceName)
+	}
+	return t
+}
+
+// Start installs the tracer and begins exporting. With no exporter
+// configured it does nothing.
+func (t *Tracer) Start() {
+	if t.exporter == nil {
+		t.log.Info("TRACE_EXPORTER is none, not exporting spans")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.done.Add(1)
+	go t.run(ctx)
+	active.Store(t)
+}
+
+// Stop uninstalls the tracer and exports what is still queued.
+func (t *Tracer) Stop() {
+	if t.cancel == nil {
+		return
+	}
+	active.CompareAndSwap(t, nil)
+	t.cancel()
+	t.done.Wait()
+}
+
+func (t *Tracer) enqueue(span *Span) {
+	select {
+	case t.spans <- span:
+	default:
+		t.dropped.Add(1)
+	}
+}
+
+func (t *Tracer) run(ctx context.Context) {
+	defer t.done.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]snapshot, 0, batchSize)
+	flush := func() {
+		if dropped := t.dropped.Swap(0); dropped > 0 {
+			t.log.Warn("Span queue is full, dropped spans", "count", dropped)
+		}
+		if len(batch) == 0 {
+			return
+		}
+		exportCtx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		defer cancel()
+		if err := t.exporter.export(exportCtx, batch); err != nil {
+			t.log.Warn("Failed to export spans", "count", len(batch), "error", err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case span := <-t.spans:
+			batch = append(batch, span.snapshot())
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			for {
+				select {
+				case span := <-t.spans:
+					batch = append(batch, span.snapshot())
+					if len(batch) >= batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
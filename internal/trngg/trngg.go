@@ -4,35 +4,70 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/circuit"
 	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/fixtures"
 	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/metrics"
 	redisclient "yk-dc-bot/internal/redisclient"
+	"yk-dc-bot/internal/schema"
+	"yk-dc-bot/internal/tracing"
 
 	"github.com/gospider007/ja3"
 	"github.com/gospider007/requests"
 	"github.com/tidwall/gjson"
 )
 
-const baseURL = "https://api.tracker.gg/api/v2/valorant/standard/profile/riot"
+// ProfileSchema covers the parts of a tracker.gg profile parsePlayerData reads.
+var ProfileSchema = schema.New("trackergg.profile",
+	schema.Required("data.platformInfo.platformUserIdentifier", schema.String),
+	schema.Optional("data.platformInfo.avatarUrl", schema.String),
+	schema.Required("data.segments", schema.Array),
+	schema.Required("data.segments.0.stats", schema.Object),
+	schema.Required("data.segments.0.stats.matchesWon.displayValue", schema.String),
+	schema.Required("data.segments.0.stats.matchesLost.displayValue", schema.String),
+	schema.Required("data.segments.0.stats.matchesWinPct.displayValue", schema.String),
+	schema.Required("data.segments.0.stats.headshotsPercentage.displayValue", schema.String),
+	schema.Required("data.segments.0.stats.kDRatio.displayValue", schema.String),
+	schema.Required("data.segments.0.stats.damagePerRound.displayValue", schema.String),
+	schema.Required("data.segments.0.stats.timePlayed.displayValue", schema.String),
+	schema.Optional("data.segments.0.stats.rank.metadata.tierName", schema.String),
+	schema.Optional("data.segments.0.stats.rank.metadata.iconUrl", schema.String),
+)
 
 type TrackerAPI struct {
+	cfg         *config.Config
+	baseURL     string
 	redisClient *redisclient.Client
 	log         *logger.Logger
 	httpClient  *requests.Client
-	proxies     []string // TODO: implement usage for when we're getting rate limited
+	fixtures    *fixtures.Store
+	schemas     *schema.Detector
+	nextProxy   atomic.Uint64
+	breaker     *circuit.Breaker
+	metrics     *metrics.Bot
 }
 
-func NewTrackerAPI(cfg *config.Config, redisClient *redisclient.Client, log *logger.Logger) *TrackerAPI {
+func NewTrackerAPI(cfg *config.Config, redisClient *redisclient.Client, log *logger.Logger, store *fixtures.Store, schemas *schema.Detector, breakers *circuit.Breakers, m *metrics.Bot) *TrackerAPI {
 	client, _ := requests.NewClient(context.TODO())
 
 	return &TrackerAPI{
+		cfg:         cfg,
+		baseURL:     cfg.APIs.TrackerBaseURL,
 		redisClient: redisClient,
 		log:         log,
 		httpClient:  client,
+		fixtures:    store,
+		schemas:     schemas,
+		breaker:     breakers.Get("trackergg"),
+		metrics:     m,
 	}
 }
 
@@ -51,8 +86,7 @@ type PlayerData struct {
 	IsPrivate      bool   `json:"isPrivate,omitempty"`
 }
 
-func (t *TrackerAPI) GetPlayerTrackerData(username, tagline string) (*PlayerData, error) {
-	ctx := context.Background()
+func (t *TrackerAPI) GetPlayerTrackerData(ctx context.Context, username, tagline string) (*PlayerData, error) {
 	cacheKey := fmt.Sprintf("tracker:%s:%s", username, tagline)
 
 	cachedData, err := t.redisClient.Get(ctx, cacheKey)
@@ -63,28 +97,44 @@ func (t *TrackerAPI) GetPlayerTrackerData(username, tagline string) (*PlayerData
 		}
 	}
 
-	playerData, err := t.fetchPlayerData(username, tagline)
+	playerData, err := t.fetchPlayerData(ctx, username, tagline)
 	if err != nil {
 		return nil, err
 	}
 
 	cacheData, _ := json.Marshal(playerData)
-	if err := t.redisClient.Set(ctx, cacheKey, string(cacheData), 30*time.Minute); err != nil {
-		t.log.Error("Failed to cache player data", "error", err)
+	if err := t.redisClient.Set(ctx, cacheKey, string(cacheData), t.cfg.Runtime().CacheTTLs.Tracker); err != nil {
+		logger.FromContext(ctx, t.log).Error("Failed to cache player data", "error", err)
 	}
 
 	return playerData, nil
 }
 
-func (t *TrackerAPI) fetchPlayerData(username, tagline string) (*PlayerData, error) {
-	url := fmt.Sprintf("%s/%s%%23%s", baseURL, username, tagline)
+func (t *TrackerAPI) fetchPlayerData(ctx context.Context, username, tagline string) (*PlayerData, error) {
+	log := logger.FromContext(ctx, t.log)
+	profileURL := fmt.Sprintf("%s/%s%%23%s", t.baseURL, username, tagline)
+
+	// the requests client doesn't take an http.RoundTripper, so fixtures are
+	// handled here rather than through fixtures.Store.Transport
+	if t.fixtures.Mode() == fixtures.ModeReplay {
+		fixture, err := t.fixtures.Load(http.MethodGet, profileURL)
+		if err != nil {
+			return nil, err
+		}
+		return t.handleResponse(fixture.Status, string(fixture.Bytes()), username, tagline)
+	}
+
+	host := "api.tracker.gg"
+	if parsed, err := url.Parse(t.baseURL); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
 
 	headers := map[string]string{
 		"Accept":          "application/json",
 		"Accept-Encoding": "gzip, deflate, br",
 		"Accept-Language": "en-US,en;q=0.9",
 		"Connection":      "keep-alive",
-		"Host":            "api.tracker.gg",
+		"Host":            host,
 		"Origin":          "https://tracker.gg",
 		"Referer":         fmt.Sprintf("https://tracker.gg/valorant/profile/riot/%s%%23%s/overview", username, tagline),
 		"Sec-Fetch-Dest":  "empty",
@@ -96,30 +146,110 @@ func (t *TrackerAPI) fetchPlayerData(username, tagline string) (*PlayerData, err
 	ja3str := "771,4865-4866-4867-49195-49199-49196-49200-52393-52392-49171-49172-156-157-47-53,18-13-65281-65037-35-23-27-5-43-45-16-17513-51-10-0-11-21,29-23-24,0"
 	ja3Spec, _ := ja3.CreateSpecWithStr(ja3str)
 
+	rateLimited := false
 	for attempts := 0; attempts < 5; attempts++ {
-		resp, err := t.httpClient.Get(context.TODO(), url, requests.RequestOption{
+		option := requests.RequestOption{
 			Headers: headers,
 			Ja3:     true,
 			Ja3Spec: ja3Spec,
-		})
+		}
+		if rateLimited {
+			option.Proxy = t.proxy()
+		}
+
+		if err := t.breaker.Allow(); err != nil {
+			return nil, err
+		}
+
+		_, span := tracing.StartClient(ctx, "GET trackergg profile",
+			tracing.Attr("http.request.method", "GET"),
+			tracing.Attr("http.route", "profile"),
+			tracing.Attr("url.full", profileURL),
+			tracing.Attr("http.request.resend_count", attempts),
+			tracing.Attr("proxied", option.Proxy != ""),
+		)
+		start := time.Now()
+		resp, err := t.httpClient.Get(ctx, profileURL, option)
 
 		if err != nil {
-			t.log.Error("Failed to fetch player data", "error", err)
+			t.breaker.RecordErr(ctx)
+			t.metrics.ObserveUpstream("trackergg", "profile", 0, start)
+			span.SetError(err)
+			span.End()
+			log.Error("Failed to fetch player data", "error", err)
+			if ctx.Err() != nil {
+				return nil, apperrors.Wrap(ctx.Err(), "TRACKER_FETCH_ERROR", "gave up fetching player data")
+			}
 			continue
 		}
+		t.breaker.Record(resp.StatusCode() < http.StatusInternalServerError)
+		t.metrics.ObserveUpstream("trackergg", "profile", resp.StatusCode(), start)
+		log.Debug("tracker.gg request", "player", username+"#"+tagline, "status", resp.StatusCode(), "duration", time.Since(start), "proxied", option.Proxy != "")
+
+		limited := resp.StatusCode() == http.StatusTooManyRequests || strings.Contains(resp.Text(), "scrape our website") || strings.Contains(resp.Text(), "You are being rate lim")
+		span.SetAttributes(tracing.Attr("http.response.status_code", resp.StatusCode()), tracing.Attr("rate_limited", limited))
+		span.End()
 
-		if strings.Contains(resp.Text(), "scrape our website") || strings.Contains(resp.Text(), "You are being rate lim") {
-			t.log.Warn("Rate limited, retrying", "attempt", attempts+1)
-			time.Sleep(time.Second)
+		if limited {
+			log.Warn("Rate limited, retrying", "attempt", attempts+1)
+			rateLimited = true
+			select {
+			case <-ctx.Done():
+				return nil, apperrors.Wrap(ctx.Err(), "TRACKER_FETCH_ERROR", "gave up retrying after a rate limit")
+			case <-time.After(time.Second):
+			}
 			continue
 		}
 
-		return t.parsePlayerData(resp.Text())
+		if t.fixtures.Mode() == fixtures.ModeRecord {
+			fixture := &fixtures.Fixture{
+				Method: http.MethodGet,
+				URL:    profileURL,
+				Status: resp.StatusCode(),
+				// the request headers are static and not worth recording
+				Headers: resp.Headers(),
+			}
+			fixture.SetBody(resp.Content())
+			if err := t.fixtures.Save(fixture); err != nil {
+				log.Warn("Failed to record fixture", "error", err)
+			}
+		}
+
+		return t.handleResponse(resp.StatusCode(), resp.Text(), username, tagline)
 	}
 
 	return nil, apperrors.New("TRACKER_FETCH_ERROR", "Failed to fetch player data after multiple attempts")
 }
 
+// proxy rotates through the configured proxies, returning "" to go direct
+// when there are none.
+func (t *TrackerAPI) proxy() string {
+	proxies := t.cfg.Runtime().TrackerProxies
+	if len(proxies) == 0 {
+		return ""
+	}
+	return proxies[(t.nextProxy.Add(1)-1)%uint64(len(proxies))]
+}
+
+func (t *TrackerAPI) handleResponse(status int, body, username, tagline string) (*PlayerData, error) {
+	switch {
+	case status == http.StatusNotFound:
+		return nil, apperrors.New("TRACKER_NOT_FOUND", fmt.Sprintf("no tracker profile for %s#%s", username, tagline), fmt.Sprintf("tracker.gg doesn't know %s#%s yet.", username, tagline))
+	case status == http.StatusTooManyRequests:
+		return nil, apperrors.New("TRACKER_RATE_LIMITED", "tracker.gg rate limited the request", "tracker.gg is rate limiting us right now, please try again in a bit.")
+	case status >= http.StatusInternalServerError:
+		return nil, apperrors.New("TRACKER_STATUS_ERROR", fmt.Sprintf("tracker.gg request failed with status code %d", status))
+	}
+
+	if !strings.Contains(body, "CollectorResultStatus::Private") {
+		if err := t.schemas.Validate(ProfileSchema, []byte(body)); err != nil {
+			return nil, err
+		}
+	}
+
+	return t.parsePlayerData(body)
+}
+
 func (t *TrackerAPI) parsePlayerData(jsonBody string) (*PlayerData, error) {
 	data := gjson.Get(jsonBody, "data")
 
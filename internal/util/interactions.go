@@ -85,8 +85,8 @@ func flagsFromOptions(resp InteractionResponse) discordgo.MessageFlags {
 	return 0
 }
 
-func SendErrorEmbed(s *discordgo.Session, i *discordgo.Interaction, errorMessage string, log *logger.Logger, footerString string) {
-	errorEmbed := NewEmbed(StyleError, "Error", errorMessage).
+func SendErrorEmbed(s *discordgo.Session, i *discordgo.Interaction, title, errorMessage string, log *logger.Logger, footerString string) {
+	errorEmbed := NewEmbed(StyleError, title, errorMessage).
 		WithFooter(footerString).
 		Build()
 
@@ -2,7 +2,10 @@ package util
 
 import (
 	"sync"
+	"sync/atomic"
 	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/i18n"
+	"yk-dc-bot/internal/metrics"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -20,8 +23,21 @@ type ProgressTracker struct {
 	EmbedStyle  EmbedStyle
 	Title       string
 	Footer      string
-	done        chan struct{}
-	once        sync.Once
+	// ErrorFooter replaces Footer on the error embed, if set.
+	ErrorFooter string
+	// Catalog and Locale translate the steps sent with SendStep and the error
+	// title.
+	Catalog *i18n.Catalog
+	Locale  discordgo.Locale
+	// Metrics counts the tracker's edits. It may be nil.
+	Metrics *metrics.Bot
+	// Guard, if set, is held across each edit. Whoever silences the tracker
+	// under it knows no edit is in flight afterwards.
+	Guard    sync.Locker
+	done     chan struct{}
+	finished chan struct{}
+	once     sync.Once
+	silenced atomic.Bool
 }
 
 func NewProgressTracker(s *discordgo.Session, i *discordgo.Interaction, title, footer string, style EmbedStyle) *ProgressTracker {
@@ -33,6 +49,7 @@ func NewProgressTracker(s *discordgo.Session, i *discordgo.Interaction, title, f
 		Title:       title,
 		Footer:      footer,
 		done:        make(chan struct{}),
+		finished:    make(chan struct{}),
 	}
 }
 
@@ -41,6 +58,7 @@ func (pt *ProgressTracker) Start() {
 }
 
 func (pt *ProgressTracker) trackProgress() {
+	defer close(pt.finished)
 	defer pt.Stop()
 	for {
 		select {
@@ -48,14 +66,22 @@ func (pt *ProgressTracker) trackProgress() {
 			if !ok {
 				return
 			}
+			if pt.silenced.Load() {
+				if update.Error != nil || update.Done {
+					return
+				}
+				continue
+			}
 			if update.Error != nil {
 				errorMessage := update.Error.Error()
-				errorEmbed := NewEmbed(StyleError, "Error", errorMessage).
-					WithFooter(pt.Footer).
+				footer := pt.Footer
+				if pt.ErrorFooter != "" {
+					footer = pt.ErrorFooter
+				}
+				errorEmbed := NewEmbed(StyleError, pt.Catalog.T(pt.Locale, "common.error"), errorMessage).
+					WithFooter(footer).
 					Build()
-				_, _ = pt.Session.InteractionResponseEdit(pt.Interaction, &discordgo.WebhookEdit{
-					Embeds: &[]*discordgo.MessageEmbed{errorEmbed},
-				})
+				pt.edit("error", errorEmbed)
 				return
 			}
 			if update.Done {
@@ -64,15 +90,33 @@ func (pt *ProgressTracker) trackProgress() {
 			progressEmbed := NewEmbed(pt.EmbedStyle, pt.Title, update.Message).
 				WithFooter(pt.Footer).
 				Build()
-			_, _ = pt.Session.InteractionResponseEdit(pt.Interaction, &discordgo.WebhookEdit{
-				Embeds: &[]*discordgo.MessageEmbed{progressEmbed},
-			})
+			pt.edit("step", progressEmbed)
 		case <-pt.done:
 			return
 		}
 	}
 }
 
+// edit replaces the response with embed, under Guard if one is set, unless
+// the tracker was silenced in the meantime.
+func (pt *ProgressTracker) edit(kind string, embed *discordgo.MessageEmbed) {
+	if pt.Guard != nil {
+		pt.Guard.Lock()
+		defer pt.Guard.Unlock()
+	}
+	if pt.silenced.Load() {
+		return
+	}
+	_, err := pt.Session.InteractionResponseEdit(pt.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	pt.Metrics.ObserveProgressEdit(kind, result)
+}
+
 func (pt *ProgressTracker) Stop() {
 	pt.once.Do(func() {
 		close(pt.done)
@@ -80,6 +124,17 @@ func (pt *ProgressTracker) Stop() {
 	})
 }
 
+// Silence stops the tracker from editing the response. Updates are still
+// accepted, so the handler doesn't block, but dropped.
+func (pt *ProgressTracker) Silence() {
+	pt.silenced.Store(true)
+}
+
+// Wait blocks until the tracker has made its last edit.
+func (pt *ProgressTracker) Wait() {
+	<-pt.finished
+}
+
 func (pt *ProgressTracker) SendUpdate(message string) {
 	select {
 	case pt.Updates <- ProgressUpdate{Message: message}:
@@ -87,6 +142,11 @@ func (pt *ProgressTracker) SendUpdate(message string) {
 	}
 }
 
+// SendStep sends the catalog message key in the tracker's locale.
+func (pt *ProgressTracker) SendStep(key string, vars ...i18n.Vars) {
+	pt.SendUpdate("> " + pt.Catalog.T(pt.Locale, key, vars...))
+}
+
 func (pt *ProgressTracker) SendError(err *apperrors.AppError) {
 	select {
 	case pt.Updates <- ProgressUpdate{Error: err}:
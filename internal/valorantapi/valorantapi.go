@@ -0,0 +1,226 @@
+package valorantapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"yk-dc-bot/internal/apperrors"
+	"yk-dc-bot/internal/background"
+	"yk-dc-bot/internal/circuit"
+	"yk-dc-bot/internal/config"
+	"yk-dc-bot/internal/fixtures"
+	"yk-dc-bot/internal/logger"
+	"yk-dc-bot/internal/metrics"
+	redisclient "yk-dc-bot/internal/redisclient"
+	"yk-dc-bot/internal/tracing"
+)
+
+type ValorantAPI struct {
+	baseURL     string
+	redisClient *redisclient.Client
+	log         *logger.Logger
+	httpClient  *http.Client
+	breaker     *circuit.Breaker
+	metrics     *metrics.Bot
+	jobs        *background.Jobs
+
+	minimapsMu sync.Mutex
+	minimaps   map[string]image.Image
+	// fetching holds the minimap downloads in flight, so concurrent requests
+	// for one map share a download and other maps don't wait on it.
+	fetching map[string]*minimapFetch
+}
+
+type minimapFetch struct {
+	done chan struct{}
+	img  image.Image
+	err  error
+}
+
+func NewValorantAPI(cfg *config.Config, redisClient *redisclient.Client, log *logger.Logger, store *fixtures.Store, breakers *circuit.Breakers, m *metrics.Bot, jobs *background.Jobs) *ValorantAPI {
+	return &ValorantAPI{
+		baseURL:     cfg.APIs.ValorantBaseURL,
+		redisClient: redisClient,
+		log:         log,
+		httpClient: &http.Client{
+			Timeout:   time.Second * 10,
+			Transport: store.Transport(http.DefaultTransport),
+		},
+		breaker:  breakers.Get("valorantapi"),
+		metrics:  m,
+		jobs:     jobs,
+		minimaps: make(map[string]image.Image),
+		fetching: make(map[string]*minimapFetch),
+	}
+}
+
+type MapData struct {
+	UUID         string  `json:"uuid"`
+	DisplayName  string  `json:"displayName"`
+	MapURL       string  `json:"mapUrl"`
+	DisplayIcon  string  `json:"displayIcon"`
+	XMultiplier  float64 `json:"xMultiplier"`
+	YMultiplier  float64 `json:"yMultiplier"`
+	XScalarToAdd float64 `json:"xScalarToAdd"`
+	YScalarToAdd float64 `json:"yScalarToAdd"`
+}
+
+// Project converts in-game coordinates into normalized (0..1) minimap
+// coordinates. The game's axes are swapped relative to the minimap image.
+func (m *MapData) Project(x, y float64) (float64, float64) {
+	return y*m.XMultiplier + m.XScalarToAdd, x*m.YMultiplier + m.YScalarToAdd
+}
+
+// get fetches url; route names it in metrics.
+func (v *ValorantAPI) get(ctx context.Context, route, url string) (body []byte, err error) {
+	ctx, span := tracing.StartClient(ctx, "GET valorantapi "+route,
+		tracing.Attr("http.request.method", "GET"),
+		tracing.Attr("http.route", route),
+		tracing.Attr("url.full", url),
+	)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "VALORANT_API_REQUEST_ERROR", "error creating request")
+	}
+
+	if err := v.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		v.breaker.RecordErr(ctx)
+		v.metrics.ObserveUpstream("valorantapi", route, 0, start)
+		return nil, apperrors.Wrap(err, "VALORANT_API_REQUEST_ERROR", "error making request")
+	}
+	defer resp.Body.Close()
+	v.breaker.Record(resp.StatusCode < http.StatusInternalServerError)
+	v.metrics.ObserveUpstream("valorantapi", route, resp.StatusCode, start)
+	span.SetAttributes(tracing.Attr("http.response.status_code", resp.StatusCode))
+
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "VALORANT_API_REQUEST_ERROR", "error reading response")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.New("VALORANT_API_STATUS_ERROR_"+strconv.Itoa(resp.StatusCode), fmt.Sprintf("valorant-api request failed with status code %d", resp.StatusCode))
+	}
+
+	return body, nil
+}
+
+func (v *ValorantAPI) GetMaps(ctx context.Context) ([]MapData, error) {
+	cacheKey := "valorantapi:maps"
+
+	cachedData, err := v.redisClient.Get(ctx, cacheKey)
+	if err == nil {
+		var maps []MapData
+		if err := json.Unmarshal([]byte(cachedData), &maps); err == nil {
+			return maps, nil
+		}
+	}
+
+	body, err := v.get(ctx, "maps", v.baseURL+"/maps")
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Status int       `json:"status"`
+		Data   []MapData `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, apperrors.Wrap(err, "MAPS_FETCH_ERROR", "Failed to fetch map data")
+	}
+
+	cacheData, _ := json.Marshal(response.Data)
+	if err := v.redisClient.Set(ctx, cacheKey, string(cacheData), 24*time.Hour); err != nil {
+		logger.FromContext(ctx, v.log).Error("Failed to cache map data", "error", err)
+	}
+
+	return response.Data, nil
+}
+
+func (v *ValorantAPI) GetMap(ctx context.Context, name string) (*MapData, error) {
+	maps, err := v.GetMaps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range maps {
+		if strings.EqualFold(maps[idx].DisplayName, name) && maps[idx].DisplayIcon != "" {
+			return &maps[idx], nil
+		}
+	}
+
+	return nil, apperrors.New("MAP_NOT_FOUND", fmt.Sprintf("no minimap data for map %s", name), "I don't have a minimap for that map yet")
+}
+
+// GetMinimap returns the map's minimap image, downloading it the first time.
+// The download is shared by everyone asking for the same map, and carries on
+// for them if the caller that started it gives up. It runs as a background
+// job, so shutdown waits for it.
+func (v *ValorantAPI) GetMinimap(ctx context.Context, m *MapData) (image.Image, error) {
+	v.minimapsMu.Lock()
+	if img, ok := v.minimaps[m.UUID]; ok {
+		v.minimapsMu.Unlock()
+		return img, nil
+	}
+	fetch, ok := v.fetching[m.UUID]
+	if !ok {
+		fetch = &minimapFetch{done: make(chan struct{})}
+		v.fetching[m.UUID] = fetch
+		v.jobs.Go(ctx, func(ctx context.Context) { v.fetchMinimap(ctx, m, fetch) })
+	}
+	v.minimapsMu.Unlock()
+
+	select {
+	case <-fetch.done:
+		return fetch.img, fetch.err
+	case <-ctx.Done():
+		return nil, apperrors.Wrap(ctx.Err(), "VALORANT_API_REQUEST_ERROR", "gave up waiting for the minimap of "+m.DisplayName)
+	}
+}
+
+func (v *ValorantAPI) fetchMinimap(ctx context.Context, m *MapData, fetch *minimapFetch) {
+	defer close(fetch.done)
+
+	fetch.img, fetch.err = v.downloadMinimap(ctx, m)
+
+	v.minimapsMu.Lock()
+	delete(v.fetching, m.UUID)
+	if fetch.err == nil {
+		v.minimaps[m.UUID] = fetch.img
+	}
+	v.minimapsMu.Unlock()
+}
+
+func (v *ValorantAPI) downloadMinimap(ctx context.Context, m *MapData) (image.Image, error) {
+	body, err := v.get(ctx, "minimap", m.DisplayIcon)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(body))
+	if err != nil {
+		return nil, apperrors.Wrap(err, "MINIMAP_DECODE_ERROR", fmt.Sprintf("failed to decode minimap for %s", m.DisplayName))
+	}
+	return img, nil
+}